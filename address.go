@@ -0,0 +1,146 @@
+package byline
+
+import (
+	"regexp"
+)
+
+// LastLine - number of the last line for LinesRange, like "$" address in sed
+const LastLine = -1
+
+// address - lines selected for the next filter functions
+type address struct {
+	active bool // the current line is selected
+	index  int  // index of the filter function for selecting lines in filterFuncs
+}
+
+// lookahead - the next line read in advance for detecting the last line
+type lookahead struct {
+	started  bool
+	ok       bool // the next line exists
+	token    []byte
+	rt       []byte
+	nr, fnr  int
+	filename string
+	curToken []byte // buffers for the current line
+	curRT    []byte
+}
+
+// Range - apply the next filter functions only to lines from the line matched by startRe
+// to the line matched by endRe inclusive, like /start/,/end/ in sed, other lines are passed through untouched.
+// endRe is checked from the next line after the start, the range can be repeated, nil endRe - to the last line,
+// nil startRe matches no lines.
+func (lr *Reader) Range(startRe, endRe *regexp.Regexp) *Reader {
+	inRange := false
+	return lr.addAddress(func(line []byte) bool {
+		if !inRange {
+			inRange = startRe != nil && startRe.Match(line)
+			return inRange
+		}
+		if endRe != nil && endRe.Match(line) {
+			inRange = false
+		}
+		return true
+	})
+}
+
+// LinesRange - apply the next filter functions only to lines with numbers (NR) from..to inclusive,
+// like "from,to" in sed, to == LastLine - to the last line, from == LastLine - only the last line ("$" in sed),
+// to < from - only the line from, like in sed
+func (lr *Reader) LinesRange(from, to int) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if to != LastLine && to < from {
+		to = from
+	}
+	if from == LastLine {
+		lr.ahead = &lookahead{}
+		return lr.addAddress(func([]byte) bool {
+			return lr.isLastLine()
+		})
+	}
+
+	return lr.addAddress(func([]byte) bool {
+		return lr.awkVars.NR >= from && (to == LastLine || lr.awkVars.NR <= to)
+	})
+}
+
+// Step - apply the next filter functions only to every step'th line starting with line number first,
+// like "first~step" in sed, step <= 0 - only the first line
+func (lr *Reader) Step(first, step int) *Reader {
+	return lr.addAddress(func([]byte) bool {
+		if step <= 0 {
+			return lr.awkVars.NR == first
+		}
+		return lr.awkVars.NR >= first && (lr.awkVars.NR-first)%step == 0
+	})
+}
+
+// EndScope - end the scope of the last address (Range, LinesRange, Step),
+// the next filter functions are applied to all lines
+func (lr *Reader) EndScope() *Reader {
+	if lr == nil {
+		return nil
+	}
+	lr.scope = nil
+	return lr
+}
+
+// addAddress - add filter function for selecting lines, the address replaces the previous one,
+// the filter function is not counted in LineError.FilterIndex
+func (lr *Reader) addAddress(match func(line []byte) bool) *Reader {
+	if lr == nil {
+		return nil
+	}
+
+	addr := &address{index: len(lr.filterFuncs)}
+	lr.addrs = append(lr.addrs, addr)
+	// the address is evaluated for all lines
+	lr.scope = nil
+	lr.MapErr(func(line []byte) ([]byte, error) {
+		body, _ := lr.trimRS(line)
+		addr.active = match(body)
+		return line, nil
+	})
+	lr.scope = addr
+	return lr
+}
+
+// isLastLine - the current line is the last line of all inputs
+func (lr *Reader) isLastLine() bool {
+	return lr.ahead != nil && lr.ahead.started && !lr.ahead.ok
+}
+
+// scanAhead - read the line read in advance and the next line
+func (lr *Reader) scanAhead() bool {
+	ahead := lr.ahead
+	if !ahead.started {
+		ahead.started = true
+		lr.readAhead()
+	}
+	if !ahead.ok {
+		return false
+	}
+
+	ahead.curToken = append(ahead.curToken[:0], ahead.token...)
+	ahead.curRT = append(ahead.curRT[:0], ahead.rt...)
+	lr.token, lr.rt = ahead.curToken, ahead.curRT
+	lr.awkVars.NR, lr.awkVars.FNR, lr.awkVars.FILENAME = ahead.nr, ahead.fnr, ahead.filename
+
+	lr.readAhead()
+	return true
+}
+
+// readAhead - read the next line in advance without changing the state of the current line
+func (lr *Reader) readAhead() {
+	ahead := lr.ahead
+	vars, rt := lr.awkVars, lr.rt
+
+	if ahead.ok = lr.scanInput(); ahead.ok {
+		ahead.token = append(ahead.token[:0], lr.scanner.Bytes()...)
+		ahead.rt = append(ahead.rt[:0], lr.rt...)
+		ahead.nr, ahead.fnr, ahead.filename = lr.awkVars.NR, lr.awkVars.FNR, lr.awkVars.FILENAME
+	}
+
+	lr.awkVars, lr.rt = vars, rt
+}
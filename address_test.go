@@ -0,0 +1,196 @@
+package byline_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddress(t *testing.T) {
+	upper := func(line string) string { return strings.ToUpper(line) }
+	tests := []struct {
+		name   string
+		in     string
+		filter func(lr *byline.Reader) *byline.Reader
+		out    string
+	}{
+		{
+			name: "range by regexp",
+			in:   "a\nbegin\nb\nend\nc\nbegin\nd\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Range(regexp.MustCompile(`^begin$`), regexp.MustCompile(`^end$`)).MapString(upper)
+			},
+			out: "a\nBEGIN\nB\nEND\nc\nBEGIN\nD\n",
+		},
+		{
+			name: "range end is checked from the next line",
+			in:   "a\nbegin end\nb\nend\nc\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Range(regexp.MustCompile(`begin`), regexp.MustCompile(`end`)).MapString(upper)
+			},
+			out: "a\nBEGIN END\nB\nEND\nc\n",
+		},
+		{
+			name: "range without end",
+			in:   "a\nbegin\nb\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Range(regexp.MustCompile(`^begin$`), nil).MapString(upper)
+			},
+			out: "a\nBEGIN\nB\n",
+		},
+		{
+			name: "grep in range",
+			in:   "a\nbegin\nb\nc\nend\nb\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Range(regexp.MustCompile(`^begin$`), regexp.MustCompile(`^end$`)).
+					GrepString(func(line string) bool { return line != "b\n" })
+			},
+			out: "a\nbegin\nc\nend\nb\n",
+		},
+		{
+			name: "lines range",
+			in:   "1\n2\n3\n4\n5\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.LinesRange(2, 3).Map(func(line []byte) []byte { return append([]byte("-"), line...) })
+			},
+			out: "1\n-2\n-3\n4\n5\n",
+		},
+		{
+			name: "lines range with to less than from",
+			in:   "1\n2\n3\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.LinesRange(2, 1).MapString(func(line string) string { return "-" + line })
+			},
+			out: "1\n-2\n3\n",
+		},
+		{
+			name: "range with nil start matches no lines",
+			in:   "1\n2\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Range(nil, regexp.MustCompile(`2`)).MapString(func(line string) string { return "-" + line })
+			},
+			out: "1\n2\n",
+		},
+		{
+			name: "lines range to the last line",
+			in:   "1\n2\n3\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.LinesRange(2, byline.LastLine).MapString(func(line string) string { return "-" + line })
+			},
+			out: "1\n-2\n-3\n",
+		},
+		{
+			name: "last line",
+			in:   "1\n2\n3",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.LinesRange(byline.LastLine, byline.LastLine).MapString(func(line string) string { return "-" + line })
+			},
+			out: "1\n2\n-3",
+		},
+		{
+			name: "last line of multiline input",
+			in:   "1\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.LinesRange(byline.LastLine, byline.LastLine).MapString(func(line string) string { return "-" + line })
+			},
+			out: "-1\n",
+		},
+		{
+			name: "step",
+			in:   "1\n2\n3\n4\n5\n6\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Step(2, 3).MapString(func(line string) string { return "-" + line })
+			},
+			out: "1\n-2\n3\n4\n-5\n6\n",
+		},
+		{
+			name: "step zero",
+			in:   "1\n2\n3\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Step(2, 0).MapString(func(line string) string { return "-" + line })
+			},
+			out: "1\n-2\n3\n",
+		},
+		{
+			name: "end scope",
+			in:   "1\n2\n3\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Step(1, 2).MapString(func(line string) string { return "-" + line }).
+					EndScope().
+					MapString(func(line string) string { return "+" + line })
+			},
+			out: "+-1\n+2\n+-3\n",
+		},
+		{
+			name: "the next address replaces scope",
+			in:   "1\n2\n3\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.LinesRange(1, 1).MapString(func(line string) string { return "-" + line }).
+					LinesRange(3, 3).MapString(func(line string) string { return "+" + line })
+			},
+			out: "-1\n2\n+3\n",
+		},
+		{
+			name: "parallel stage in scope",
+			in:   "1\n2\n3\n4\n5\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Step(1, 2).
+					ParallelMap(2, func(line []byte) ([]byte, error) { return append([]byte("-"), line...), nil }).
+					MapString(func(line string) string { return "+" + line })
+			},
+			out: "+-1\n2\n+-3\n4\n+-5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.filter(byline.NewReader(strings.NewReader(tt.in))).ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, tt.out, result)
+		})
+	}
+}
+
+func TestLastLineMultiReader(t *testing.T) {
+	lr := byline.NewMultiReader(
+		byline.NamedReader{Name: "a", Reader: strings.NewReader("1\n2\n")},
+		byline.NamedReader{Name: "b", Reader: strings.NewReader("3\n4\n")},
+	).
+		LinesRange(byline.LastLine, byline.LastLine).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			return "last", nil
+		}).
+		EndScope().
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			return strings.Join([]string{vars.FILENAME, line}, ":"), nil
+		})
+
+	result, err := lr.ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "a:1\na:2\nb:3\nb:last\n", result)
+}
+
+func TestAddressFilterIndex(t *testing.T) {
+	errBad := errors.New("bad")
+	_, err := byline.NewReader(strings.NewReader("1\n2\nbad\n")).
+		MapString(strings.TrimSpace).
+		Step(1, 1).
+		Map(func(line []byte) []byte { return line }).
+		EndScope().
+		MapErr(func(line []byte) ([]byte, error) {
+			if string(line) == "bad" {
+				return line, errBad
+			}
+			return line, nil
+		}).
+		ReadAll()
+
+	var lineErr *byline.LineError
+	require.ErrorAs(t, err, &lineErr)
+	require.Equal(t, 2, lineErr.FilterIndex)
+	require.Equal(t, 3, lineErr.NR)
+}
@@ -0,0 +1,165 @@
+package byline
+
+// AnyOfOptions - options for GrepAnyOfOptions
+type AnyOfOptions struct {
+	WholeWord  bool // patterns are matched only as whole words, preceded and followed by non-word characters like grep -w
+	IgnoreCase bool // case-insensitive matching of ASCII letters
+}
+
+// ahoCorasick - matcher of many patterns at once by Aho-Corasick automaton,
+// transitions are built for all bytes (DFA), so matching doesn't use failure links.
+// Bytes are mapped to classes: each byte from patterns has own class, all other bytes share class 0,
+// so the table of transitions is small for typical patterns
+type ahoCorasick struct {
+	classes   [256]int32 // class of byte
+	stride    int        // number of classes
+	trans     []int32    // transitions: trans[state*stride+class] - the next state
+	depth     []int32    // length of the path from the root to the state
+	terminal  []bool     // a pattern ends in the state
+	matched   []bool     // a pattern ends in the state or in a suffix of the state
+	dict      []int32    // the nearest terminal state by failure links, 0 - none
+	wholeWord bool
+	matchAll  bool // the empty pattern is matched any line
+}
+
+// newAhoCorasick - build the matcher for patterns
+func newAhoCorasick(patterns [][]byte, opts AnyOfOptions) *ahoCorasick {
+	ac := &ahoCorasick{wholeWord: opts.WholeWord}
+	ac.setClasses(patterns, opts.IgnoreCase)
+	ac.addState(0)
+
+	for _, pattern := range patterns {
+		if len(pattern) == 0 {
+			ac.matchAll = ac.matchAll || !opts.WholeWord
+			continue
+		}
+
+		state := int32(0)
+		for _, b := range pattern {
+			idx := int(state)*ac.stride + int(ac.classes[b])
+			// the root is never the next state in the trie, 0 - no transition
+			if ac.trans[idx] == 0 {
+				ac.trans[idx] = ac.addState(ac.depth[state] + 1)
+			}
+			state = ac.trans[idx]
+		}
+		ac.terminal[state] = true
+		ac.matched[state] = true
+	}
+
+	ac.build()
+	return ac
+}
+
+// setClasses - set classes of bytes, letters in both cases have the same class for ignore case mode
+func (ac *ahoCorasick) setClasses(patterns [][]byte, ignoreCase bool) {
+	ac.stride = 1
+	for _, pattern := range patterns {
+		for _, b := range pattern {
+			if ignoreCase {
+				b = toLowerASCII(b)
+			}
+			if ac.classes[b] == 0 {
+				ac.classes[b] = int32(ac.stride)
+				ac.stride++
+			}
+		}
+	}
+
+	if ignoreCase {
+		for b := 'A'; b <= 'Z'; b++ {
+			ac.classes[b] = ac.classes[toLowerASCII(byte(b))]
+		}
+	}
+}
+
+// addState - add the state without transitions
+func (ac *ahoCorasick) addState(depth int32) int32 {
+	ac.trans = append(ac.trans, make([]int32, ac.stride)...)
+	ac.depth = append(ac.depth, depth)
+	ac.terminal = append(ac.terminal, false)
+	ac.matched = append(ac.matched, false)
+	ac.dict = append(ac.dict, 0)
+	return int32(len(ac.depth) - 1)
+}
+
+// build - set failure transitions by breadth-first traversal of the trie
+func (ac *ahoCorasick) build() {
+	fail := make([]int32, len(ac.depth))
+	queue := make([]int32, 0, len(ac.depth))
+
+	for class := 0; class < ac.stride; class++ {
+		if next := ac.trans[class]; next != 0 {
+			queue = append(queue, next)
+		}
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for class := 0; class < ac.stride; class++ {
+			idx := int(state)*ac.stride + class
+			next := ac.trans[idx]
+			failNext := ac.trans[int(fail[state])*ac.stride+class]
+			if next == 0 {
+				ac.trans[idx] = failNext
+				continue
+			}
+
+			fail[next] = failNext
+			ac.matched[next] = ac.matched[next] || ac.matched[failNext]
+			if ac.terminal[failNext] {
+				ac.dict[next] = failNext
+			} else {
+				ac.dict[next] = ac.dict[failNext]
+			}
+			queue = append(queue, next)
+		}
+	}
+}
+
+// match - the line contains any of patterns
+func (ac *ahoCorasick) match(line []byte) bool {
+	if ac.matchAll {
+		return true
+	}
+
+	state := int32(0)
+	for i, b := range line {
+		state = ac.trans[int(state)*ac.stride+int(ac.classes[b])]
+		if !ac.matched[state] {
+			continue
+		}
+		if !ac.wholeWord {
+			return true
+		}
+
+		end := i + 1
+		for found := state; found > 0; found = ac.dict[found] {
+			if ac.terminal[found] && isWordBoundary(line, end-int(ac.depth[found]), end) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isWordBoundary - the line[start:end] is preceded and followed by non-word characters or the line boundaries
+func isWordBoundary(line []byte, start, end int) bool {
+	return (start == 0 || !isWordChar(line[start-1])) && (end == len(line) || !isWordChar(line[end]))
+}
+
+// isWordChar - ASCII word character like \w in regexp
+func isWordChar(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// toLowerASCII - convert ASCII letter to lower case
+func toLowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
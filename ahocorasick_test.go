@@ -0,0 +1,104 @@
+package byline_test
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepAnyOf(t *testing.T) {
+	in := "user 42 login\nuser 420 logout\nadmin login\nUSER_7 error\nguest he-she\n"
+
+	tests := []struct {
+		name     string
+		patterns []string
+		opts     byline.AnyOfOptions
+		out      string
+	}{
+		{name: "one pattern", patterns: []string{"login"}, out: "user 42 login\nadmin login\n"},
+		{name: "many patterns", patterns: []string{"admin", "logout", "he"}, out: "user 420 logout\nadmin login\nguest he-she\n"},
+		{name: "overlapped patterns", patterns: []string{"user 4200", "er 42"}, out: "user 42 login\nuser 420 logout\n"},
+		{name: "suffix of other pattern", patterns: []string{"abcd", "bc", "xyz"}, out: ""},
+		{name: "no patterns", patterns: nil, out: ""},
+		{name: "empty pattern", patterns: []string{""}, out: in},
+		{name: "empty pattern for whole words", patterns: []string{""}, opts: byline.AnyOfOptions{WholeWord: true}, out: ""},
+		{name: "whole word", patterns: []string{"42"}, opts: byline.AnyOfOptions{WholeWord: true}, out: "user 42 login\n"},
+		{name: "whole word in the end", patterns: []string{"she", "login"}, opts: byline.AnyOfOptions{WholeWord: true}, out: "user 42 login\nadmin login\nguest he-she\n"},
+		{name: "whole word by shorter pattern", patterns: []string{"user 42", "42"}, opts: byline.AnyOfOptions{WholeWord: true}, out: "user 42 login\n"},
+		{name: "word with underscore", patterns: []string{"USER"}, opts: byline.AnyOfOptions{WholeWord: true}, out: ""},
+		{name: "case sensitive", patterns: []string{"user_"}, out: ""},
+		{name: "ignore case", patterns: []string{"user_", "ADMIN"}, opts: byline.AnyOfOptions{IgnoreCase: true}, out: "admin login\nUSER_7 error\n"},
+		{name: "ignore case whole word", patterns: []string{"User"}, opts: byline.AnyOfOptions{IgnoreCase: true, WholeWord: true}, out: "user 42 login\nuser 420 logout\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patterns [][]byte
+			for _, pattern := range tt.patterns {
+				patterns = append(patterns, []byte(pattern))
+			}
+
+			result, err := byline.NewReader(strings.NewReader(in)).GrepAnyOfOptions(patterns, tt.opts).ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, tt.out, result)
+		})
+	}
+}
+
+func TestGrepAnyOfAsRegexp(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randWord := func(maxLen int) string {
+		word := make([]byte, 1+rnd.Intn(maxLen))
+		for i := range word {
+			word[i] = "abcAB _"[rnd.Intn(7)]
+		}
+		return string(word)
+	}
+
+	var lines []string
+	for i := 0; i < 300; i++ {
+		lines = append(lines, randWord(20))
+	}
+	in := strings.Join(lines, "\n") + "\n"
+
+	for _, opts := range []byline.AnyOfOptions{{}, {WholeWord: true}, {IgnoreCase: true}, {WholeWord: true, IgnoreCase: true}} {
+		for i := 0; i < 20; i++ {
+			var (
+				patterns [][]byte
+				quoted   []string
+			)
+			for j := 0; j < 1+rnd.Intn(5); j++ {
+				pattern := strings.Trim(randWord(4), " ")
+				if pattern == "" {
+					continue
+				}
+				patterns = append(patterns, []byte(pattern))
+				quoted = append(quoted, regexp.QuoteMeta(pattern))
+			}
+			if len(patterns) == 0 {
+				continue
+			}
+
+			if opts.WholeWord {
+				// like grep -w: pattern is preceded and followed by non-word characters
+				for j, q := range quoted {
+					quoted[j] = `(?:^|\W)` + q + `(?:\W|$)`
+				}
+			}
+			expr := strings.Join(quoted, "|")
+			if opts.IgnoreCase {
+				expr = "(?i)" + expr
+			}
+
+			expected, err := byline.NewReader(strings.NewReader(in)).GrepByRegexp(regexp.MustCompile(expr)).ReadAllString()
+			require.NoError(t, err)
+			result, err := byline.NewReader(strings.NewReader(in)).GrepAnyOfOptions(patterns, opts).ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, expected, result, "patterns: %q, options: %+v", patterns, opts)
+		}
+	}
+}
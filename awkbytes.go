@@ -0,0 +1,160 @@
+package byline
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// AWKModeBytes - process lines with AWK like mode without converting to strings, faster version of AWKMode.
+// Fields are sub-slices of the line, the slice of fields is reused between lines,
+// so do not save them or the line, and do not change them if they are used in the result.
+// Default and literal field separators are processed without regexp.
+func (lr *Reader) AWKModeBytes(filterFn func(line []byte, fields [][]byte, vars AWKVars) ([]byte, error)) *Reader {
+	if lr == nil {
+		return nil
+	}
+
+	var fields [][]byte
+	return lr.MapErr(func(fullLine []byte) ([]byte, error) {
+		line, RS := lr.trimRS(fullLine)
+
+		var err error
+		if fields, err = lr.splitFieldsBytes(line, fields[:0]); err != nil {
+			return nullBytes, err
+		}
+		lr.awkVars.NF = len(fields)
+		lr.setRT()
+
+		result, err := filterFn(line, fields, lr.awkVars)
+		if err != nil {
+			return nullBytes, err
+		}
+
+		switch {
+		case len(RS) == 0 || bytes.HasSuffix(result, RS):
+			return result, nil
+		case len(result) > 0 && len(result) == len(line) && &result[0] == &line[0]:
+			// the line is not changed
+			return fullLine, nil
+		default:
+			// result can be a part of the read buffer, so append to the copy
+			return append(result[:len(result):len(result)], RS...), nil
+		}
+	})
+}
+
+// splitFieldsBytes - split line to fields for AWK mode, appends fields to dst
+func (lr *Reader) splitFieldsBytes(line []byte, dst [][]byte) ([][]byte, error) {
+	switch {
+	case lr.csv != nil:
+		fields, err := lr.csv.split(string(line))
+		if err != nil {
+			return dst, err
+		}
+		for _, field := range fields {
+			dst = append(dst, []byte(field))
+		}
+		return dst, nil
+	case lr.widths != nil:
+		return splitByWidthsBytes(line, lr.widths, dst), nil
+	case lr.fpat != nil:
+		for _, loc := range lr.fpat.FindAllIndex(line, -1) {
+			dst = append(dst, line[loc[0]:loc[1]])
+		}
+		return dst, nil
+	case lr.paragraph:
+		for {
+			i := bytes.IndexByte(line, '\n')
+			if i < 0 {
+				return lr.splitByFS(line, dst), nil
+			}
+			dst = lr.splitByFS(line[:i], dst)
+			line = line[i+1:]
+		}
+	default:
+		return lr.splitByFS(line, dst), nil
+	}
+}
+
+// splitByFS - split line by FS like regexp.Split, with fast paths for default and literal separators
+func (lr *Reader) splitByFS(line []byte, dst [][]byte) [][]byte {
+	FS := lr.awkVars.FS
+	if FS == defaultFS || FS.String() == defaultFS.String() {
+		return splitBySpaces(line, dst)
+	}
+	if literal, complete := FS.LiteralPrefix(); complete && literal != "" {
+		return splitByLiteral(line, []byte(literal), dst)
+	}
+
+	// the same algorithm as in regexp.Split
+	if len(line) == 0 {
+		return append(dst, line)
+	}
+	beg, end := 0, 0
+	for _, match := range FS.FindAllIndex(line, -1) {
+		end = match[0]
+		if match[1] != 0 {
+			dst = append(dst, line[beg:end])
+		}
+		beg = match[1]
+	}
+	if end != len(line) {
+		dst = append(dst, line[beg:])
+	}
+	return dst
+}
+
+// isSpace - check byte for \s class of regexp
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\f' || b == '\r'
+}
+
+// splitBySpaces - split line by `\s+` regexp
+func splitBySpaces(line []byte, dst [][]byte) [][]byte {
+	beg := 0
+	for i := 0; i < len(line); {
+		if !isSpace(line[i]) {
+			i++
+			continue
+		}
+
+		dst = append(dst, line[beg:i])
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		beg = i
+	}
+
+	return append(dst, line[beg:])
+}
+
+// splitByLiteral - split line by literal separator
+func splitByLiteral(line, sep []byte, dst [][]byte) [][]byte {
+	for {
+		i := bytes.Index(line, sep)
+		if i < 0 {
+			return append(dst, line)
+		}
+		dst = append(dst, line[:i])
+		line = line[i+len(sep):]
+	}
+}
+
+// splitByWidthsBytes - split line to fields by fixed widths
+func splitByWidthsBytes(line []byte, widths []int, dst [][]byte) [][]byte {
+	for _, width := range widths {
+		if len(line) == 0 {
+			break
+		}
+
+		end := 0
+		for count := 0; count < width && end < len(line); count++ {
+			_, size := utf8.DecodeRune(line[end:])
+			end += size
+		}
+		dst = append(dst, line[:end])
+		line = line[end:]
+	}
+
+	return dst
+}
@@ -0,0 +1,140 @@
+package byline_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWKModeBytesFieldsAsAWKMode(t *testing.T) {
+	inputs := []string{
+		"",
+		"\n",
+		"a b c\n",
+		"  a \t b  \n",
+		"a,b,,c,\n,x\n",
+		"a;b, c ;;\n",
+		"no separators",
+		"\n\n\n",
+	}
+	separators := []*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`\s+`),
+		regexp.MustCompile(`,`),
+		regexp.MustCompile(`, `),
+		regexp.MustCompile(`[,;]\s*`),
+		regexp.MustCompile(`;*`),
+	}
+
+	for i, in := range inputs {
+		for _, fs := range separators {
+			t.Run(fmt.Sprintf("%d %v", i, fs), func(t *testing.T) {
+				fieldsStr := [][]string{}
+				lr := byline.NewReader(strings.NewReader(in))
+				if fs != nil {
+					lr.SetFS(fs)
+				}
+				require.NoError(t, lr.AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+					fieldsStr = append(fieldsStr, fields)
+					return line, nil
+				}).Discard())
+
+				fieldsBytes := [][]string{}
+				lr = byline.NewReader(strings.NewReader(in))
+				if fs != nil {
+					lr.SetFS(fs)
+				}
+				require.NoError(t, lr.AWKModeBytes(func(line []byte, fields [][]byte, vars byline.AWKVars) ([]byte, error) {
+					require.Equal(t, len(fields), vars.NF)
+					row := []string{}
+					for _, field := range fields {
+						row = append(row, string(field))
+					}
+					fieldsBytes = append(fieldsBytes, row)
+					return line, nil
+				}).Discard())
+
+				require.Equal(t, fieldsStr, fieldsBytes)
+			})
+		}
+	}
+}
+
+func TestAWKModeBytes(t *testing.T) {
+	t.Run("result", func(t *testing.T) {
+		reader := strings.NewReader("1 one\n2 two\n3 three")
+
+		result, err := byline.NewReader(reader).
+			AWKModeBytes(func(line []byte, fields [][]byte, vars byline.AWKVars) ([]byte, error) {
+				switch vars.NR {
+				case 1:
+					return line, nil
+				case 2:
+					return nil, byline.ErrOmitLine
+				}
+				return fields[1], nil
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1 one\nthree", result)
+	})
+
+	t.Run("field as result does not break the next lines", func(t *testing.T) {
+		reader := strings.NewReader("a b\nc d\ne f\n")
+
+		result, err := byline.NewReader(reader).
+			AWKModeBytes(func(line []byte, fields [][]byte, vars byline.AWKVars) ([]byte, error) {
+				return fields[0], nil
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "a\nc\ne\n", result)
+	})
+
+	t.Run("other fields modes", func(t *testing.T) {
+		cases := []struct {
+			name string
+			lr   *byline.Reader
+			out  string
+		}{
+			{
+				name: "CSV",
+				lr:   byline.NewReader(strings.NewReader("\"a,b\",c\n")).SetCSV(byline.CSVOptions{}),
+				out:  "a,b|c\n",
+			},
+			{
+				name: "field widths",
+				lr:   byline.NewReader(strings.NewReader("abcdef\n")).SetFieldWidths(2, 3),
+				out:  "ab|cde\n",
+			},
+			{
+				name: "FPAT",
+				lr:   byline.NewReader(strings.NewReader("a1b22c333\n")).SetFPAT(regexp.MustCompile(`\d+`)),
+				out:  "1|22|333\n",
+			},
+			{
+				name: "paragraph",
+				lr:   byline.NewReader(strings.NewReader("a b\nc\n\nd\n")).ParagraphMode(),
+				out:  "a|b|c\n\nd\n",
+			},
+		}
+
+		for _, row := range cases {
+			t.Run(row.name, func(t *testing.T) {
+				result, err := row.lr.AWKModeBytes(func(line []byte, fields [][]byte, vars byline.AWKVars) ([]byte, error) {
+					parts := []string{}
+					for _, field := range fields {
+						parts = append(parts, string(field))
+					}
+					return []byte(strings.Join(parts, "|")), nil
+				}).ReadAllString()
+				require.NoError(t, err)
+				require.Equal(t, row.out, result)
+			})
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/msoap/byline"
@@ -153,3 +155,70 @@ func Benchmark_AWKMode(b *testing.B) {
 		require.True(b, len(res) > len(bytesSlice)/2-1)
 	}
 }
+
+func Benchmark_AWKModeBytes(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		reader := bytes.NewReader(bytesSlice)
+		res, err := byline.NewReader(reader).AWKModeBytes(func(line []byte, _ [][]byte, vars byline.AWKVars) ([]byte, error) {
+			if vars.NR%2 == 0 {
+				return nil, byline.ErrOmitLine
+			}
+			return line, nil
+		}).ReadAll()
+		require.NoError(b, err)
+		require.True(b, len(res) > len(bytesSlice)/2-1)
+	}
+}
+
+func getPatterns() [][]byte {
+	patterns := make([][]byte, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		patterns = append(patterns, []byte(fmt.Sprintf("%d line", 100000+i*7)))
+	}
+	patterns = append(patterns, []byte("77 line"))
+	return patterns
+}
+
+func Benchmark_GrepAnyOf(b *testing.B) {
+	patterns := getPatterns()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader := bytes.NewReader(bytesSlice)
+		res, err := byline.NewReader(reader).GrepAnyOf(patterns).ReadAll()
+		require.NoError(b, err)
+		require.True(b, len(res) > 0)
+	}
+}
+
+func Benchmark_GrepByRegexpAlternation(b *testing.B) {
+	var quoted []string
+	for _, pattern := range getPatterns() {
+		quoted = append(quoted, regexp.QuoteMeta(string(pattern)))
+	}
+	re := regexp.MustCompile(strings.Join(quoted, "|"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader := bytes.NewReader(bytesSlice)
+		res, err := byline.NewReader(reader).GrepByRegexp(re).ReadAll()
+		require.NoError(b, err)
+		require.True(b, len(res) > 0)
+	}
+}
+
+func Benchmark_GrepFixed(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		reader := bytes.NewReader(bytesSlice)
+		res, err := byline.NewReader(reader).GrepFixed([]byte("77 line")).ReadAll()
+		require.NoError(b, err)
+		require.True(b, len(res) > 0)
+	}
+}
+
+func Benchmark_GrepFixedFold(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		reader := bytes.NewReader(bytesSlice)
+		res, err := byline.NewReader(reader).GrepFixedFold([]byte("77 LINE")).ReadAll()
+		require.NoError(b, err)
+		require.True(b, len(res) > 0)
+	}
+}
@@ -3,10 +3,12 @@ package byline
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"io/ioutil"
 	"regexp"
+	"strings"
 )
 
 var (
@@ -20,6 +22,10 @@ var (
 	defaultFS = regexp.MustCompile(`\s+`)
 	// default line separator
 	defaultRS byte = '\n'
+	// line separator for paragraph mode: blank lines or the last newline
+	paragraphRS = regexp.MustCompile(`\n\n+|\n$`)
+	// default output field separator
+	defaultOFS = " "
 	// for Grep* methods
 	nullBytes = []byte{}
 	// bytes.Buffer growth to this limit
@@ -33,14 +39,45 @@ type Reader struct {
 	existsData  bool
 	filterFuncs []func(line []byte) ([]byte, error)
 	awkVars     AWKVars
+	rs          []byte                      // record separator, can be multi-byte
+	rsRe        *regexp.Regexp              // record separator as regexp, used instead of rs if set
+	rt          []byte                      // terminator of the current record
+	paragraph   bool                        // paragraph mode, records are separated by blank lines
+	skipNL      bool                        // skip leading newlines in paragraph mode
+	maxLineSize int                         // max size of line with separator
+	longLines   LongLinePolicy              // what to do with lines longer than maxLineSize
+	truncated   []byte                      // beginning of the truncated long line
+	chunk       []byte                      // chunk of the split long line, waits for the separator right after it
+	line        []byte                      // copy of the current line before filters
+	maxErrors   int                         // number of skipped lines with errors, 0 - stop on the first error, < 0 - unlimited
+	errs        []*LineError                // collected errors from skipped lines
+	beginFuncs  []func() string             // output before the first line
+	endFuncs    []func(vars AWKVars) string // output after the last line
+	begun       bool                        // output of Begin functions is written
+	ended       bool                        // output of End functions is written
+	inputs      []NamedReader               // next inputs for multi reader
+	csv         *CSVOptions                 // CSV mode for fields splitting
+	widths      []int                       // fixed widths of fields
+	fpat        *regexp.Regexp              // regexp for fields content
+	ctx         context.Context             // context for cancellation, can be nil
+	stages      []*parallelStage            // filters processed in parallel
+	token       []byte                      // the current line from the scanner
+	ahead       *lookahead                  // the next line read in advance, for the last line address
+	addrs       []*address                  // all addresses of lines
+	scope       *address                    // address for the next filters, nil - all lines
 }
 
 // AWKVars - settings for AWK mode, see man awk
 type AWKVars struct {
-	NR int            // number of the current line (begin from 1)
-	NF int            // number of fields in the current line
-	RS byte           // record separator, default is '\n'
-	FS *regexp.Regexp // field separator, default is `\s+`
+	NR       int            // number of the current line (begin from 1)
+	FNR      int            // number of the current line in the current input (begin from 1)
+	NF       int            // number of fields in the current line
+	RS       byte           // record separator, default is '\n', 0 for multi-byte or regexp separator
+	FS       *regexp.Regexp // field separator, default is `\s+`
+	RT       string         // input text that terminated the current record (matched RS)
+	OFS      string         // output field separator for AWKModeFields, default is " "
+	ORS      string         // output record separator for AWKModeFields, default is "" - terminator of the input record
+	FILENAME string         // name of the current input for NewMultiReader
 }
 
 // NewReader - get new line by line Reader
@@ -49,81 +86,318 @@ func NewReader(reader io.Reader) *Reader {
 		return nil
 	}
 	lr := &Reader{
-		scanner:    bufio.NewScanner(reader),
 		existsData: true,
 		awkVars: AWKVars{
-			RS: defaultRS,
-			FS: defaultFS,
+			RS:  defaultRS,
+			FS:  defaultFS,
+			OFS: defaultOFS,
 		},
+		rs:          []byte{defaultRS},
+		maxLineSize: bufio.MaxScanTokenSize,
 	}
 
-	lr.scanner.Split(lr.scanLinesBySep)
+	lr.setScanner(reader)
 	lr.buffer.Grow(bufferSizeLimit)
 
 	return lr
 }
 
+// setScanner - set scanner for reading from the new input
+func (lr *Reader) setScanner(reader io.Reader) {
+	lr.scanner = bufio.NewScanner(reader)
+	lr.scanner.Split(lr.scanLinesBySep)
+	lr.scanner.Buffer(nil, lr.maxLineSize)
+	lr.skipNL = lr.paragraph
+	lr.awkVars.FNR = 0
+}
+
+// scan - read the next line to lr.token
+func (lr *Reader) scan() bool {
+	if lr.ahead != nil {
+		return lr.scanAhead()
+	}
+	if !lr.scanInput() {
+		return false
+	}
+	lr.token = lr.scanner.Bytes()
+	return true
+}
+
+// terminateInput - the last line of the input without separator is terminated by RS if the next inputs exist,
+// so it is not merged with the first line of the next input
+func (lr *Reader) terminateInput(token []byte) []byte {
+	if len(lr.inputs) == 0 || len(token) == 0 {
+		lr.rt = nil
+		return token
+	}
+
+	rs := lr.rs
+	if lr.rsRe != nil {
+		rs = []byte{defaultRS}
+	}
+	token = append(append([]byte{}, token...), rs...)
+	lr.rt = token[len(token)-len(rs):]
+	return token
+}
+
+// scanInput - read the next line from the current or the next inputs
+func (lr *Reader) scanInput() bool {
+	for !lr.scanner.Scan() {
+		if lr.scanner.Err() != nil || len(lr.inputs) == 0 {
+			return false
+		}
+		lr.awkVars.FILENAME = lr.inputs[0].Name
+		lr.setScanner(lr.inputs[0].Reader)
+		lr.inputs = lr.inputs[1:]
+	}
+
+	lr.awkVars.NR++
+	lr.awkVars.FNR++
+	return true
+}
+
 func (lr *Reader) scanLinesBySep(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	if atEOF && len(data) == 0 {
+	if atEOF && len(data) == 0 && lr.truncated == nil && lr.chunk == nil {
+		return 0, nil, nil
+	}
+	if lr.chunk != nil {
+		return lr.nextChunk(data, atEOF)
+	}
+	if lr.skipNL {
+		i := 0
+		for i < len(data) && data[i] == '\n' {
+			i++
+		}
+		if i > 0 {
+			return i, nil, nil
+		}
+		lr.skipNL = false
+	}
+
+	full := len(data) >= lr.maxLineSize
+	start, end := lr.findRS(data)
+	if lr.rsRe != nil && end == len(data) && !atEOF && !full {
+		// match at the end of data, it can be longer after the next read
 		return 0, nil, nil
 	}
-	if i := bytes.IndexByte(data, lr.awkVars.RS); i >= 0 {
+
+	if lr.truncated != nil {
+		return lr.skipTruncated(data, start, end, atEOF)
+	}
+	if end > 0 {
 		// We have a full RS-terminated line.
-		return i + 1, data[0 : i+1], nil
+		lr.rt = data[start:end]
+		return end, data[0:end], nil
 	}
 	// If we're at EOF, we have a final, non-terminated line. Return it.
 	if atEOF {
-		return len(data), data, nil
+		return len(data), lr.terminateInput(data), nil
+	}
+	if full {
+		return lr.longLine(data)
 	}
 
 	// Request more data.
 	return 0, nil, nil
 }
 
+// findRS - find the first record separator in data, returns zeroes if not found
+func (lr *Reader) findRS(data []byte) (start, end int) {
+	switch {
+	case lr.rsRe != nil:
+		return lr.findRSRegexp(data)
+	case lr.csv != nil:
+		return lr.csv.findRS(data, lr.rs)
+	case len(lr.rs) == 1:
+		if i := bytes.IndexByte(data, lr.rs[0]); i >= 0 {
+			return i, i + 1
+		}
+	default:
+		// partial separator at the end of data will be completed by the next read
+		if i := bytes.Index(data, lr.rs); i >= 0 {
+			return i, i + len(lr.rs)
+		}
+	}
+
+	return 0, 0
+}
+
+// findRSRegexp - find the first non-empty match of RS regexp, returns zeroes if not found
+func (lr *Reader) findRSRegexp(data []byte) (start, end int) {
+	for offset := 0; offset < len(data); {
+		loc := lr.rsRe.FindIndex(data[offset:])
+		if loc == nil {
+			break
+		}
+		if loc[1] > loc[0] {
+			return offset + loc[0], offset + loc[1]
+		}
+		offset += loc[1] + 1
+	}
+
+	return 0, 0
+}
+
 // Read - implement io.Reader interface
 func (lr *Reader) Read(p []byte) (n int, err error) {
 	if lr == nil {
 		return 0, ErrNilReader
 	}
+
 	var (
-		bufErr, filterErr error
-		lineBytes         []byte
+		bufErr    error
+		lineBytes []byte
 	)
+	for bufErr == nil && lr.buffer.Len() < bufferSizeLimit {
+		lineBytes, _, bufErr = lr.next()
+		_, _ = lr.buffer.Write(lineBytes) // #nosec - err always is nil
+	}
+	if bufErr == io.EOF {
+		// return the rest of the buffer, io.EOF will be returned for the empty buffer
+		bufErr = nil
+	}
 
-	for lr.existsData && bufErr == nil && lr.buffer.Len() < bufferSizeLimit {
-		if lr.existsData = lr.scanner.Scan(); !lr.existsData {
-			break
+	n, err = lr.buffer.Read(p)
+	if err != nil && bufErr == nil {
+		bufErr = err
+	}
+
+	return n, bufErr
+}
+
+// next - get the next processed line or output of Begin/End functions,
+// ok is false for omitted lines, returns io.EOF after the end of data
+func (lr *Reader) next() (lineBytes []byte, ok bool, err error) {
+	if !lr.begun {
+		lr.begun = true
+		if len(lr.beginFuncs) > 0 {
+			for _, beginFn := range lr.beginFuncs {
+				lineBytes = append(lineBytes, beginFn()...)
+			}
+			return lineBytes, true, nil
 		}
+	}
+
+	if lr.existsData && lr.ctx != nil {
+		if err := lr.ctx.Err(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if lr.existsData && len(lr.stages) > 0 {
+		lineBytes, ok, err = lr.nextParallel()
+		if err != io.EOF {
+			return lineBytes, ok, err
+		}
+		lr.existsData = false
+	}
+
+	if lr.existsData {
+		if lr.existsData = lr.scan(); lr.existsData {
+			return lr.processLine(lr.token)
+		}
+	}
+
+	if err := lr.scanner.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if !lr.ended {
+		lr.ended = true
+		if len(lr.endFuncs) > 0 {
+			for _, endFn := range lr.endFuncs {
+				lineBytes = append(lineBytes, endFn(lr.awkVars)...)
+			}
+			return lineBytes, true, nil
+		}
+	}
+
+	return nil, false, io.EOF
+}
+
+// processLine - apply all filter functions to the line, ok is false for omitted lines
+func (lr *Reader) processLine(lineBytes []byte) (result []byte, ok bool, err error) {
+	// save original line for errors, filters can change it in place
+	lr.line = append(lr.line[:0], lineBytes...)
 
-		lineBytes = lr.scanner.Bytes()
-		lr.awkVars.NR++
-
-		for _, filterFunc := range lr.filterFuncs {
-			lineBytes, filterErr = filterFunc(lineBytes)
-			if filterErr != nil {
-				switch filterErr {
-				case ErrOmitLine:
-					lineBytes = nullBytes
-				default:
-					bufErr = filterErr
+	result, ok, err = lr.processFilters(lineBytes, 0, len(lr.filterFuncs))
+	if err == io.EOF {
+		// stop reading, but return the rest of the buffer
+		lr.existsData = false
+		return result, ok, nil
+	}
+	return result, ok, err
+}
+
+// processFilters - apply filter functions from..to-1 to the line, ok is false for omitted lines,
+// returns io.EOF if the reading must be stopped after this line: the line from filter function
+// which returns io.EOF is not processed by the next filters, after errStopAfterLine the next filters are applied
+func (lr *Reader) processFilters(lineBytes []byte, from, to int) (result []byte, ok bool, err error) {
+	var stop error
+	for i := from; i < to; i++ {
+		lineBytes, err = lr.filterFuncs[i](lineBytes)
+		if err != nil {
+			switch err {
+			case ErrOmitLine:
+				return nullBytes, false, stop
+			case io.EOF:
+				return lineBytes, true, io.EOF
+			case errStopAfterLine:
+				stop = io.EOF
+			default:
+				if err = lr.lineError(i, err); err == nil {
+					// skip line with error
+					return nullBytes, false, stop
 				}
-				break
+				return lineBytes, true, err
 			}
 		}
+	}
 
-		_, _ = lr.buffer.Write(lineBytes) // #nosec - err always is nil
+	return lineBytes, true, stop
+}
+
+// Begin - add function for output before the first line, like BEGIN block in awk
+func (lr *Reader) Begin(beginFn func() string) *Reader {
+	if lr == nil {
+		return nil
 	}
+	lr.beginFuncs = append(lr.beginFuncs, beginFn)
+	return lr
+}
 
-	if !lr.existsData && bufErr == nil {
-		bufErr = lr.scanner.Err()
+// End - add function for output after the last line, like END block in awk.
+// It is also called if a filter function stops reading with io.EOF, but not on errors.
+func (lr *Reader) End(endFn func(vars AWKVars) string) *Reader {
+	if lr == nil {
+		return nil
 	}
+	lr.endFuncs = append(lr.endFuncs, endFn)
+	return lr
+}
 
-	n, err = lr.buffer.Read(p)
-	if err != nil && bufErr == nil {
-		bufErr = err
+// WithContext - set context for cancellation, Read checks it between lines and returns ctx.Err() if it is done.
+// The context is passed to MapCtx filter functions.
+func (lr *Reader) WithContext(ctx context.Context) *Reader {
+	if lr == nil {
+		return nil
 	}
+	lr.ctx = ctx
+	return lr
+}
 
-	return n, bufErr
+// MapCtx - set filter function with context (see WithContext) for process each line, returns error if needed
+func (lr *Reader) MapCtx(filterFn func(ctx context.Context, line []byte) ([]byte, error)) *Reader {
+	if lr == nil {
+		return nil
+	}
+	return lr.MapErr(func(line []byte) ([]byte, error) {
+		ctx := lr.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return filterFn(ctx, line)
+	})
 }
 
 // Map - set filter function for process each line
@@ -141,6 +415,16 @@ func (lr *Reader) MapErr(filterFn func([]byte) ([]byte, error)) *Reader {
 	if lr == nil {
 		return nil
 	}
+	if scope := lr.scope; scope != nil {
+		// lines out of the address are passed through untouched
+		addressedFn := filterFn
+		filterFn = func(line []byte) ([]byte, error) {
+			if !scope.active {
+				return line, nil
+			}
+			return addressedFn(line)
+		}
+	}
 	lr.filterFuncs = append(lr.filterFuncs, filterFn)
 	return lr
 }
@@ -213,11 +497,14 @@ func (lr *Reader) GrepString(filterFn func(string) bool) *Reader {
 	})
 }
 
-// GrepByRegexp - grep lines by regexp
+// GrepByRegexp - grep lines by regexp, literal regexp is matched without regexp engine
 func (lr *Reader) GrepByRegexp(re *regexp.Regexp) *Reader {
 	if lr == nil {
 		return nil
 	}
+	if literal, complete := re.LiteralPrefix(); complete {
+		return lr.GrepFixed([]byte(literal))
+	}
 	return lr.Grep(func(line []byte) bool {
 		return re.Match(line)
 	})
@@ -229,6 +516,65 @@ func (lr *Reader) SetRS(rs byte) *Reader {
 		return nil
 	}
 	lr.awkVars.RS = rs
+	lr.rs = []byte{rs}
+	lr.rsRe = nil
+	lr.paragraph, lr.skipNL = false, false
+	return lr
+}
+
+// SetRSBytes - set multi-byte lines (records) separator, for example "\r\n" or "\n\n".
+// Empty separator is ignored.
+func (lr *Reader) SetRSBytes(rs []byte) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if len(rs) == 0 {
+		return lr
+	}
+	if len(rs) == 1 {
+		return lr.SetRS(rs[0])
+	}
+
+	lr.awkVars.RS = 0
+	lr.rs = append([]byte{}, rs...)
+	lr.rsRe = nil
+	lr.paragraph, lr.skipNL = false, false
+	return lr
+}
+
+// SetRSString - set multi-byte lines (records) separator as string
+func (lr *Reader) SetRSString(rs string) *Reader {
+	if lr == nil {
+		return nil
+	}
+	return lr.SetRSBytes([]byte(rs))
+}
+
+// SetRSRegexp - set lines (records) separator as regexp, like RS in GNU awk.
+// The text matched by the regexp is available in AWK mode as RT variable.
+// Empty matches are ignored, and "^"/"$" anchors match the bounds of the read buffer, not the lines.
+func (lr *Reader) SetRSRegexp(rs *regexp.Regexp) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if rs == nil {
+		return lr
+	}
+	lr.awkVars.RS = 0
+	lr.rsRe = rs
+	lr.paragraph, lr.skipNL = false, false
+	return lr
+}
+
+// ParagraphMode - set paragraph mode, like RS="" in awk: records are separated by one or more blank lines,
+// leading newlines are skipped, and newline is an additional field separator in AWK mode.
+func (lr *Reader) ParagraphMode() *Reader {
+	if lr == nil {
+		return nil
+	}
+	lr.awkVars.RS = 0
+	lr.rsRe = paragraphRS
+	lr.paragraph, lr.skipNL = true, true
 	return lr
 }
 
@@ -237,39 +583,133 @@ func (lr *Reader) SetFS(fs *regexp.Regexp) *Reader {
 	if lr == nil {
 		return nil
 	}
+	lr.resetFieldsMode()
 	lr.awkVars.FS = fs
 	return lr
 }
 
+// SetOFS - set output field separator for AWKModeFields
+func (lr *Reader) SetOFS(ofs string) *Reader {
+	if lr == nil {
+		return nil
+	}
+	lr.awkVars.OFS = ofs
+	return lr
+}
+
+// SetORS - set output record separator for AWKModeFields, empty string - use the input record terminator
+func (lr *Reader) SetORS(ors string) *Reader {
+	if lr == nil {
+		return nil
+	}
+	lr.awkVars.ORS = ors
+	return lr
+}
+
 // AWKMode - process lines with AWK like mode
 func (lr *Reader) AWKMode(filterFn func(line string, fields []string, vars AWKVars) (string, error)) *Reader {
 	if lr == nil {
 		return nil
 	}
 	return lr.MapErr(func(line []byte) ([]byte, error) {
-		addRS := false
-		RS := []byte{lr.awkVars.RS}
-		if bytes.HasSuffix(line, RS) {
-			addRS = true
-			line = bytes.TrimSuffix(line, RS)
+		lineStr, fields, RS, err := lr.awkLine(line)
+		if err != nil {
+			return nullBytes, err
 		}
-
-		lineStr := string(line)
-		fields := lr.awkVars.FS.Split(lineStr, -1)
-		lr.awkVars.NF = len(fields)
 		result, err := filterFn(lineStr, fields, lr.awkVars)
 		if err != nil {
 			return nullBytes, err
 		}
 
 		resultBytes := []byte(result)
-		if !bytes.HasSuffix(resultBytes, RS) && addRS {
-			resultBytes = append(resultBytes, lr.awkVars.RS)
+		if !bytes.HasSuffix(resultBytes, RS) {
+			resultBytes = append(resultBytes, RS...)
 		}
 		return resultBytes, nil
 	})
 }
 
+// AWKModeFields - process lines with AWK like mode, filterFn returns fields of the new line,
+// which are joined with OFS and terminated with ORS (like "$3 = x; print" in awk)
+func (lr *Reader) AWKModeFields(filterFn func(line string, fields []string, vars AWKVars) ([]string, error)) *Reader {
+	if lr == nil {
+		return nil
+	}
+	return lr.MapErr(func(line []byte) ([]byte, error) {
+		lineStr, fields, RS, err := lr.awkLine(line)
+		if err != nil {
+			return nullBytes, err
+		}
+		result, err := filterFn(lineStr, fields, lr.awkVars)
+		if err != nil {
+			return nullBytes, err
+		}
+
+		resultBytes := []byte(strings.Join(result, lr.awkVars.OFS))
+		if lr.awkVars.ORS != "" {
+			return append(resultBytes, lr.awkVars.ORS...), nil
+		}
+		return append(resultBytes, RS...), nil
+	})
+}
+
+// awkLine - prepare line for AWK mode: trim record separator, split to fields and set AWK vars,
+// returns separator or nil if line is not terminated
+func (lr *Reader) awkLine(line []byte) (lineStr string, fields []string, RS []byte, err error) {
+	line, RS = lr.trimRS(line)
+	lineStr = string(line)
+	if fields, err = lr.splitFields(lineStr); err != nil {
+		return "", nil, nil, err
+	}
+	lr.awkVars.NF = len(fields)
+	lr.setRT()
+	return lineStr, fields, RS, nil
+}
+
+// trimRS - trim record separator from the line, returns separator or nil if line is not terminated
+func (lr *Reader) trimRS(line []byte) ([]byte, []byte) {
+	RS := lr.rs
+	if lr.rsRe != nil {
+		RS = lr.rt
+	}
+	if len(RS) > 0 && bytes.HasSuffix(line, RS) {
+		return line[:len(line)-len(RS)], RS
+	}
+	return line, nil
+}
+
+// setRT - set RT AWK variable, without allocation if it is not changed
+func (lr *Reader) setRT() {
+	if lr.awkVars.RT != string(lr.rt) {
+		lr.awkVars.RT = string(lr.rt)
+	}
+}
+
+// splitFields - split line to fields for AWK mode
+func (lr *Reader) splitFields(line string) ([]string, error) {
+	if lr.csv != nil {
+		return lr.csv.split(line)
+	}
+	if lr.widths != nil {
+		return splitByWidths(line, lr.widths), nil
+	}
+	if lr.fpat != nil {
+		if fields := lr.fpat.FindAllString(line, -1); fields != nil {
+			return fields, nil
+		}
+		return []string{}, nil
+	}
+	if !lr.paragraph {
+		return lr.awkVars.FS.Split(line, -1), nil
+	}
+
+	fields := []string{}
+	for _, subLine := range strings.Split(line, "\n") {
+		fields = append(fields, lr.awkVars.FS.Split(subLine, -1)...)
+	}
+	return fields, nil
+}
+
 // Discard - read all content from Reader for side effect from filter functions
 func (lr *Reader) Discard() error {
 	if lr == nil {
@@ -279,15 +719,20 @@ func (lr *Reader) Discard() error {
 	return err
 }
 
-// ReadAllSlice - read all content from Reader by lines to slice of []byte
+// ReadAllSlice - read all content from Reader by lines to slice of []byte,
+// output of Begin/End functions is included as separate items
 func (lr *Reader) ReadAllSlice() ([][]byte, error) {
 	result := [][]byte{}
-	err := lr.Map(func(line []byte) []byte {
-		result = append(result, line)
-		return nullBytes
-	}).Discard()
+	for line, err := range lr.Lines() {
+		if err != nil {
+			return result, err
+		}
+		if len(line) > 0 {
+			result = append(result, append([]byte{}, line...))
+		}
+	}
 
-	return result, err
+	return result, nil
 }
 
 // ReadAll - read all content from Reader to slice of bytes
@@ -298,18 +743,20 @@ func (lr *Reader) ReadAll() ([]byte, error) {
 	return ioutil.ReadAll(lr)
 }
 
-// ReadAllSliceString - read all content from Reader to string slice by lines
+// ReadAllSliceString - read all content from Reader to string slice by lines,
+// output of Begin/End functions is included as separate items
 func (lr *Reader) ReadAllSliceString() ([]string, error) {
-	if lr == nil {
-		return []string{}, ErrNilReader
-	}
 	result := []string{}
-	err := lr.MapString(func(line string) string {
-		result = append(result, line)
-		return ""
-	}).Discard()
+	for line, err := range lr.Strings() {
+		if err != nil {
+			return result, err
+		}
+		if line != "" {
+			result = append(result, line)
+		}
+	}
 
-	return result, err
+	return result, nil
 }
 
 // ReadAllString - read all content from Reader to one string
@@ -2,6 +2,7 @@ package byline_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -470,3 +471,381 @@ func TestLongLines(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, []byte("90123456789012345678901234567890123456789\n<01234567890123456789012345678901234567890123456789"), rest)
 }
+
+func TestSetRSBytes(t *testing.T) {
+	cases := []struct {
+		name, rs, in string
+		out          []string
+	}{
+		{
+			name: "CRLF",
+			rs:   "\r\n",
+			in:   "111\r\n222\r\n333",
+			out:  []string{"111\r\n", "222\r\n", "333"},
+		},
+		{
+			name: "CR without LF",
+			rs:   "\r\n",
+			in:   "111\r222\r\n333\n444\r\n",
+			out:  []string{"111\r222\r\n", "333\n444\r\n"},
+		},
+		{
+			name: "paragraphs",
+			rs:   "\n\n",
+			in:   "1\n2\n\n3\n\n\n4",
+			out:  []string{"1\n2\n\n", "3\n\n", "\n4"},
+		},
+		{
+			name: "boundary",
+			rs:   "--boundary--",
+			in:   "part 1--boundary--part 2--boundary--",
+			out:  []string{"part 1--boundary--", "part 2--boundary--"},
+		},
+		{
+			name: "one byte",
+			rs:   "#",
+			in:   "1#2",
+			out:  []string{"1#", "2"},
+		},
+		{
+			name: "empty separator is ignored",
+			rs:   "",
+			in:   "1\n2",
+			out:  []string{"1\n", "2"},
+		},
+	}
+
+	for _, row := range cases {
+		t.Run(row.name, func(t *testing.T) {
+			result, err := byline.NewReader(strings.NewReader(row.in)).SetRSString(row.rs).ReadAllSliceString()
+			require.NoError(t, err)
+			require.Equal(t, row.out, result)
+		})
+	}
+
+	t.Run("separator across buffer boundaries", func(t *testing.T) {
+		line := strings.Repeat("x", 4095)
+		in := line + "\r\n" + line + "\r\n"
+		result, err := byline.NewReader(strings.NewReader(in)).SetRSBytes([]byte("\r\n")).ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{line + "\r\n", line + "\r\n"}, result)
+	})
+}
+
+func TestAWKModeMultiByteRS(t *testing.T) {
+	reader := strings.NewReader("1 one\r\n2 two\r\n3 three")
+
+	result, err := byline.NewReader(reader).
+		SetRSString("\r\n").
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			return fields[1] + "=" + fields[0], nil
+		}).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "one=1\r\ntwo=2\r\nthree=3", result)
+}
+
+func TestSetRSRegexp(t *testing.T) {
+	cases := []struct {
+		name, rs, in string
+		out          []string
+	}{
+		{
+			name: "newlines",
+			rs:   `\n+`,
+			in:   "1\n\n\n2\n3\n\n",
+			out:  []string{"1\n\n\n", "2\n", "3\n\n"},
+		},
+		{
+			name: "mixed line endings",
+			rs:   `\r?\n`,
+			in:   "1\r\n2\n3",
+			out:  []string{"1\r\n", "2\n", "3"},
+		},
+		{
+			name: "empty matches are ignored",
+			rs:   `;*`,
+			in:   "1;;2;3",
+			out:  []string{"1;;", "2;", "3"},
+		},
+		{
+			name: "no matches",
+			rs:   `#`,
+			in:   "1\n2",
+			out:  []string{"1\n2"},
+		},
+	}
+
+	for _, row := range cases {
+		t.Run(row.name, func(t *testing.T) {
+			result, err := byline.NewReader(strings.NewReader(row.in)).
+				SetRSRegexp(regexp.MustCompile(row.rs)).
+				ReadAllSliceString()
+			require.NoError(t, err)
+			require.Equal(t, row.out, result)
+		})
+	}
+
+	t.Run("match across buffer boundaries", func(t *testing.T) {
+		line := strings.Repeat("x", 4094)
+		in := line + "\n\n\n\n" + line
+		result, err := byline.NewReader(strings.NewReader(in)).
+			SetRSRegexp(regexp.MustCompile(`\n+`)).
+			ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{line + "\n\n\n\n", line}, result)
+	})
+}
+
+func TestAWKModeRT(t *testing.T) {
+	reader := strings.NewReader("1 a;;2 b;3 c")
+
+	rts := []string{}
+	result, err := byline.NewReader(reader).
+		SetRSRegexp(regexp.MustCompile(`;+`)).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			rts = append(rts, vars.RT)
+			return fields[1], nil
+		}).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "a;;b;c", result)
+	require.Equal(t, []string{";;", ";", ""}, rts)
+}
+
+func TestParagraphMode(t *testing.T) {
+	cases := []struct {
+		name, in string
+		out      []string
+	}{
+		{
+			name: "simple",
+			in:   "a 1\nb 2\n\nc 3\n",
+			out:  []string{"a 1\nb 2\n\n", "c 3\n"},
+		},
+		{
+			name: "leading and multiple blank lines",
+			in:   "\n\n\na 1\n\n\n\nb 2\nc 3",
+			out:  []string{"a 1\n\n\n\n", "b 2\nc 3"},
+		},
+		{
+			name: "only newlines",
+			in:   "\n\n\n",
+			out:  []string{},
+		},
+		{
+			name: "empty",
+			in:   "",
+			out:  []string{},
+		},
+	}
+
+	for _, row := range cases {
+		t.Run(row.name, func(t *testing.T) {
+			result, err := byline.NewReader(strings.NewReader(row.in)).ParagraphMode().ReadAllSliceString()
+			require.NoError(t, err)
+			require.Equal(t, row.out, result)
+		})
+	}
+
+	t.Run("AWK mode", func(t *testing.T) {
+		reader := strings.NewReader("Name: Bob\nAge: 42\n\n\nName: Alice\nAge: 33\n")
+
+		result, err := byline.NewReader(reader).
+			ParagraphMode().
+			SetFS(regexp.MustCompile(`:\s*`)).
+			AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+				require.Equal(t, 4, vars.NF)
+				return fmt.Sprintf("%d. %s=%s", vars.NR, fields[1], fields[3]), nil
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1. Bob=42\n\n\n2. Alice=33\n", result)
+	})
+}
+
+func TestAWKModeFields(t *testing.T) {
+	t.Run("default OFS and ORS", func(t *testing.T) {
+		reader := strings.NewReader("1  a   x\n2 b y\n3 c z")
+
+		result, err := byline.NewReader(reader).
+			AWKModeFields(func(line string, fields []string, vars byline.AWKVars) ([]string, error) {
+				if vars.NR == 2 {
+					return nil, byline.ErrOmitLine
+				}
+				fields[1] = strings.ToUpper(fields[1])
+				return fields, nil
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1 A x\n3 C z", result)
+	})
+
+	t.Run("custom OFS and ORS", func(t *testing.T) {
+		reader := strings.NewReader("1,a,x#2,b,y#3,c,z")
+
+		result, err := byline.NewReader(reader).
+			SetRS('#').
+			SetFS(regexp.MustCompile(`,`)).
+			SetOFS(";").
+			SetORS("\n").
+			AWKModeFields(func(line string, fields []string, vars byline.AWKVars) ([]string, error) {
+				require.Equal(t, ";", vars.OFS)
+				require.Equal(t, "\n", vars.ORS)
+				return []string{fields[2], fields[0]}, nil
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "x;1\ny;2\nz;3\n", result)
+	})
+}
+
+func TestBeginEnd(t *testing.T) {
+	t.Run("AWK report", func(t *testing.T) {
+		reader := strings.NewReader("a 1\nb 2\nc 3\n")
+
+		sum := 0
+		result, err := byline.NewReader(reader).
+			Begin(func() string { return "name,value\n" }).
+			AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+				value, err := strconv.Atoi(fields[1])
+				sum += value
+				return fields[0] + "," + fields[1], err
+			}).
+			End(func(vars byline.AWKVars) string {
+				return fmt.Sprintf("lines: %d, sum: %d\n", vars.NR, sum)
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "name,value\na,1\nb,2\nc,3\nlines: 3, sum: 6\n", result)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		result, err := byline.NewReader(strings.NewReader("")).
+			Begin(func() string { return "begin 1\n" }).
+			Begin(func() string { return "begin 2\n" }).
+			End(func(vars byline.AWKVars) string { return fmt.Sprintf("end %d\n", vars.NR) }).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "begin 1\nbegin 2\nend 0\n", result)
+	})
+
+	t.Run("stop by io.EOF", func(t *testing.T) {
+		result, err := byline.NewReader(strings.NewReader("1\n2\n3\n")).
+			MapStringErr(func(line string) (string, error) {
+				if line == "2\n" {
+					return "", io.EOF
+				}
+				return line, nil
+			}).
+			End(func(vars byline.AWKVars) string { return fmt.Sprintf("end %d\n", vars.NR) }).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1\nend 2\n", result)
+	})
+
+	t.Run("read all to slice", func(t *testing.T) {
+		newReader := func() *byline.Reader {
+			return byline.NewReader(strings.NewReader("a\nb\n")).
+				Begin(func() string { return "begin\n" }).
+				End(func(vars byline.AWKVars) string { return fmt.Sprintf("end %d\n", vars.NR) })
+		}
+
+		lines, err := newReader().ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{"begin\n", "a\n", "b\n", "end 2\n"}, lines)
+
+		linesBytes, err := newReader().ReadAllSlice()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("begin\n"), []byte("a\n"), []byte("b\n"), []byte("end 2\n")}, linesBytes)
+	})
+
+	t.Run("no End on error", func(t *testing.T) {
+		result, err := byline.NewReader(strings.NewReader("1\n2\n3\n")).
+			MapStringErr(func(line string) (string, error) {
+				if line == "2\n" {
+					return "", fmt.Errorf("error")
+				}
+				return line, nil
+			}).
+			End(func(vars byline.AWKVars) string { return "end\n" }).
+			ReadAllString()
+		require.Error(t, err)
+		require.Equal(t, "1\n", result)
+	})
+}
+
+func TestStopByEOFWithSmallBuffer(t *testing.T) {
+	lr := byline.NewReader(strings.NewReader("111\n222\n333\n444\n")).
+		MapStringErr(func(line string) (string, error) {
+			if line == "333\n" {
+				return line, io.EOF
+			}
+			return line, nil
+		})
+
+	result := []byte{}
+	buf := make([]byte, 3)
+	for {
+		n, err := lr.Read(buf)
+		result = append(result, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	require.Equal(t, "111\n222\n333\n", string(result))
+}
+
+func TestWithContext(t *testing.T) {
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lines := 0
+		err := byline.NewReader(strings.NewReader("1\n2\n3\n4\n5\n")).
+			WithContext(ctx).
+			Each(func([]byte) {
+				lines++
+				if lines == 2 {
+					cancel()
+				}
+			}).
+			Discard()
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 2, lines)
+	})
+
+	t.Run("not canceled", func(t *testing.T) {
+		result, err := byline.NewReader(strings.NewReader("1\n2\n")).
+			WithContext(context.Background()).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1\n2\n", result)
+	})
+
+	t.Run("MapCtx", func(t *testing.T) {
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "prefix:")
+
+		result, err := byline.NewReader(strings.NewReader("1\n2\n")).
+			MapCtx(func(ctx context.Context, line []byte) ([]byte, error) {
+				return append([]byte(ctx.Value(ctxKey{}).(string)), line...), nil
+			}).
+			WithContext(ctx).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "prefix:1\nprefix:2\n", result)
+	})
+
+	t.Run("MapCtx without context", func(t *testing.T) {
+		result, err := byline.NewReader(strings.NewReader("1\n")).
+			MapCtx(func(ctx context.Context, line []byte) ([]byte, error) {
+				require.NotNil(t, ctx)
+				return line, ctx.Err()
+			}).
+			ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1\n", result)
+	})
+}
@@ -0,0 +1,163 @@
+package byline
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CSVOptions - options for CSV fields splitting in AWK mode, see SetCSV
+type CSVOptions struct {
+	Comma            rune // field delimiter, default is ','
+	Quote            rune // quote character, default is '"'
+	Comment          rune // lines beginning with this character are omitted in AWK mode, 0 - disabled
+	LazyQuotes       bool // a quote may appear in an unquoted field and a non-doubled quote may appear in a quoted field
+	TrimLeadingSpace bool // leading white space in a field is ignored
+}
+
+// SetCSV - set CSV fields splitting for AWK mode instead of FS, by encoding/csv rules.
+// Quoted fields can contain separators of fields and records (multi-line fields),
+// for regexp record separator and paragraph mode the multi-line fields are not supported.
+func (lr *Reader) SetCSV(opts CSVOptions) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+	if opts.Quote == 0 {
+		opts.Quote = '"'
+	}
+	lr.resetFieldsMode()
+	lr.csv = &opts
+	return lr
+}
+
+// findRS - find the first record separator outside of quoted fields, returns zeroes if not found
+func (opts *CSVOptions) findRS(data []byte, rs []byte) (start, end int) {
+	if opts.Comment != 0 && bytes.HasPrefix(data, []byte(string(opts.Comment))) {
+		// quotes in comments are not special
+		if i := bytes.Index(data, rs); i >= 0 {
+			return i, i + len(rs)
+		}
+		return 0, 0
+	}
+
+	quote, comma := []byte(string(opts.Quote)), []byte(string(opts.Comma))
+	fieldStart, inQuotes := true, false
+
+	for i := 0; i < len(data); {
+		switch {
+		case inQuotes && bytes.HasPrefix(data[i:], quote):
+			if bytes.HasPrefix(data[i+len(quote):], quote) {
+				// escaped quote
+				i += 2 * len(quote)
+				continue
+			}
+			inQuotes = false
+			i += len(quote)
+		case inQuotes:
+			i++
+		case fieldStart && bytes.HasPrefix(data[i:], quote):
+			inQuotes, fieldStart = true, false
+			i += len(quote)
+		case bytes.HasPrefix(data[i:], rs):
+			return i, i + len(rs)
+		case bytes.HasPrefix(data[i:], comma):
+			fieldStart = true
+			i += len(comma)
+		default:
+			// the same white space as in split and encoding/csv
+			if fieldStart && opts.TrimLeadingSpace {
+				if r, size := utf8.DecodeRune(data[i:]); unicode.IsSpace(r) {
+					i += size
+					continue
+				}
+			}
+			fieldStart = false
+			i++
+		}
+	}
+
+	return 0, 0
+}
+
+// split - split line to fields by CSV rules
+func (opts *CSVOptions) split(line string) ([]string, error) {
+	if opts.Comment != 0 && strings.HasPrefix(line, string(opts.Comment)) {
+		return nil, ErrOmitLine
+	}
+	// like encoding/csv: CRLF line ending is trimmed and empty lines are skipped
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return nil, ErrOmitLine
+	}
+
+	quote, comma := string(opts.Quote), string(opts.Comma)
+	fields := []string{}
+	for {
+		if opts.TrimLeadingSpace {
+			line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		}
+
+		if !strings.HasPrefix(line, quote) {
+			// unquoted field
+			field, rest, found := line, "", false
+			if i := strings.Index(line, comma); i >= 0 {
+				field, rest, found = line[:i], line[i+len(comma):], true
+			}
+			if !opts.LazyQuotes && strings.Contains(field, quote) {
+				return nil, csv.ErrBareQuote
+			}
+			fields = append(fields, field)
+			if !found {
+				return fields, nil
+			}
+			line = rest
+			continue
+		}
+
+		// quoted field
+		field := strings.Builder{}
+		line = line[len(quote):]
+		for {
+			i := strings.Index(line, quote)
+			if i < 0 {
+				if !opts.LazyQuotes {
+					return nil, csv.ErrQuote
+				}
+				writeQuoted(&field, line)
+				return append(fields, field.String()), nil
+			}
+
+			writeQuoted(&field, line[:i])
+			line = line[i+len(quote):]
+			switch {
+			case strings.HasPrefix(line, quote):
+				// escaped quote
+				field.WriteString(quote)
+				line = line[len(quote):]
+				continue
+			case strings.HasPrefix(line, comma):
+				line = line[len(comma):]
+			case line == "":
+				return append(fields, field.String()), nil
+			case opts.LazyQuotes:
+				field.WriteString(quote)
+				continue
+			default:
+				return nil, csv.ErrQuote
+			}
+
+			break
+		}
+		fields = append(fields, field.String())
+	}
+}
+
+// writeQuoted - write the part of quoted field, CRLF in multi-line field is converted to LF like in encoding/csv
+func writeQuoted(field *strings.Builder, part string) {
+	field.WriteString(strings.ReplaceAll(part, "\r\n", "\n"))
+}
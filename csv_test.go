@@ -0,0 +1,148 @@
+package byline_test
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCSV(t *testing.T) {
+	cases := []struct {
+		name string
+		opts byline.CSVOptions
+		in   string
+		out  [][]string
+		err  error
+	}{
+		{
+			name: "simple",
+			in:   "a,b,c\n1,,3\n",
+			out:  [][]string{{"a", "b", "c"}, {"1", "", "3"}},
+		},
+		{
+			name: "quoted fields",
+			in:   `"a,1","b ""2""",c` + "\n" + `"",x`,
+			out:  [][]string{{"a,1", `b "2"`, "c"}, {"", "x"}},
+		},
+		{
+			name: "multi-line fields",
+			in:   "1,\"line 1\nline 2\",x\n2,\"\"\"\n\"\"\",y\n3,z",
+			out:  [][]string{{"1", "line 1\nline 2", "x"}, {"2", "\"\n\"", "y"}, {"3", "z"}},
+		},
+		{
+			name: "custom delimiter, quote and comment",
+			opts: byline.CSVOptions{Comma: ';', Quote: '\'', Comment: '#'},
+			in:   "# comment\n'a;1';b\n# comment 2\n'it''s';c\n",
+			out:  [][]string{{"a;1", "b"}, {"it's", "c"}},
+		},
+		{
+			name: "unicode delimiter",
+			opts: byline.CSVOptions{Comma: '→'},
+			in:   "a→\"b→c\"→d\n",
+			out:  [][]string{{"a", "b→c", "d"}},
+		},
+		{
+			name: "trim leading space",
+			opts: byline.CSVOptions{TrimLeadingSpace: true},
+			in:   "a,  b,\t \"c,d\"\n",
+			out:  [][]string{{"a", "b", "c,d"}},
+		},
+		{
+			name: "quote in comment",
+			opts: byline.CSVOptions{Comment: '#'},
+			in:   "# note: a,\"b\nx,y\nz,w\n",
+			out:  [][]string{{"x", "y"}, {"z", "w"}},
+		},
+		{
+			name: "CRLF",
+			in:   "a,\"b\"\r\nc,d\r\n",
+			out:  [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name: "CRLF in quoted field",
+			in:   "a,\"b\r\nc\",d\r\n",
+			out:  [][]string{{"a", "b\nc", "d"}},
+		},
+		{
+			name: "empty lines are skipped",
+			in:   "a,b\n\n\r\nc,d\n",
+			out:  [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name: "trim leading unicode space",
+			opts: byline.CSVOptions{TrimLeadingSpace: true},
+			in:   "a,\u00a0\"b,c\",\u2003d\n",
+			out:  [][]string{{"a", "b,c", "d"}},
+		},
+		{
+			name: "trim leading space with tab delimiter",
+			opts: byline.CSVOptions{Comma: '\t', TrimLeadingSpace: true},
+			in:   "a\t\t \"b\tc\"\n",
+			out:  [][]string{{"a", "b\tc"}},
+		},
+		{
+			name: "bare quote",
+			in:   "a,b\"c\n",
+			err:  csv.ErrBareQuote,
+		},
+		{
+			name: "lazy quotes",
+			opts: byline.CSVOptions{LazyQuotes: true},
+			in:   "a,b\"c,\"d\"e\"\n",
+			out:  [][]string{{"a", "b\"c", "d\"e"}},
+		},
+		{
+			name: "extraneous quote",
+			in:   "a,\"b\"c\n",
+			err:  csv.ErrQuote,
+		},
+		{
+			name: "not terminated quote",
+			in:   "a,\"b\n",
+			err:  csv.ErrQuote,
+		},
+	}
+
+	for _, row := range cases {
+		t.Run(row.name, func(t *testing.T) {
+			result := [][]string{}
+			err := byline.NewReader(strings.NewReader(row.in)).
+				SetCSV(row.opts).
+				AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+					require.Equal(t, len(fields), vars.NF)
+					result = append(result, fields)
+					return line, nil
+				}).Discard()
+			if row.err != nil {
+				require.ErrorIs(t, err, row.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, row.out, result)
+		})
+	}
+
+	t.Run("CRLF in quoted field with CRLF separator", func(t *testing.T) {
+		var result [][]string
+		err := byline.NewReader(strings.NewReader("a,\"b\r\nc\",d\r\n")).
+			SetRSString("\r\n").
+			SetCSV(byline.CSVOptions{}).
+			AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+				result = append(result, fields)
+				return line, nil
+			}).Discard()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{"a", "b\nc", "d"}}, result)
+	})
+
+	t.Run("multi-line field keeps the record", func(t *testing.T) {
+		result, err := byline.NewReader(strings.NewReader("1,\"a\nb\"\n2,c\n")).
+			SetCSV(byline.CSVOptions{}).
+			ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{"1,\"a\nb\"\n", "2,c\n"}, result)
+	})
+}
@@ -0,0 +1,202 @@
+package byline
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decoder - decoder of fields to struct fields by `byline` tags
+type decoder struct {
+	fields []decoderField
+	byName bool // some fields are defined by column names, the first line is the header
+}
+
+// decoderField - struct field and the source of its value
+type decoderField struct {
+	name   string // struct field name
+	index  int    // index of struct field
+	column string // column name from the header
+	number int    // field number as $N in awk, 0 - whole line, -1 - column is used
+	layout string // layout for time.Time
+}
+
+// Decode - process lines in AWK mode with decoding fields to struct T, struct fields are mapped by tags:
+//
+//	`byline:"2"` - the field number as $2 in awk ("0" - the whole line);
+//	`byline:"price"` - the column name, the first line is the header (see AWKModeHeader);
+//	`layout:"2006-01-02"` - layout for time.Time fields, default is time.RFC3339.
+//
+// Supported types are strings, ints, uints, floats, bools, time.Time and encoding.TextUnmarshaler implementations.
+// Conversion errors are returned from Read as LineError with the line number.
+// Decode panics if T is not a struct or has unsupported tagged fields, like regexp.MustCompile.
+func Decode[T any](lr *Reader, filterFn func(rec T, vars AWKVars) (string, error)) *Reader {
+	if lr == nil {
+		return nil
+	}
+
+	dec, err := newDecoder(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		panic(err)
+	}
+
+	decodeFn := func(rec Record, vars AWKVars) (string, error) {
+		var value T
+		if err := dec.decode(reflect.ValueOf(&value).Elem(), rec); err != nil {
+			return "", err
+		}
+		return filterFn(value, vars)
+	}
+
+	if dec.byName {
+		return lr.AWKModeHeader(decodeFn)
+	}
+	return lr.AWKMode(func(line string, fields []string, vars AWKVars) (string, error) {
+		return decodeFn(Record{Line: line, Fields: fields}, vars)
+	})
+}
+
+// newDecoder - get decoder for struct type
+func newDecoder(structType reflect.Type) (*decoder, error) {
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("decode: %s is not a struct", structType)
+	}
+
+	dec := &decoder{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, ok := field.Tag.Lookup("byline")
+		if !ok || tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("decode: field %s is unexported", field.Name)
+		}
+		if !isDecodable(field.Type) {
+			return nil, fmt.Errorf("decode: field %s has unsupported type %s", field.Name, field.Type)
+		}
+
+		decField := decoderField{
+			name:   field.Name,
+			index:  i,
+			layout: field.Tag.Get("layout"),
+		}
+		if decField.layout == "" {
+			decField.layout = time.RFC3339
+		}
+		if number, err := strconv.Atoi(tag); err == nil && number >= 0 {
+			decField.number = number
+		} else {
+			decField.number = -1
+			decField.column = tag
+			dec.byName = true
+		}
+		dec.fields = append(dec.fields, decField)
+	}
+
+	return dec, nil
+}
+
+// isDecodable - check if the type is supported by decoder
+func isDecodable(fieldType reflect.Type) bool {
+	if fieldType == timeType || reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// decode - set struct fields from the record
+func (dec *decoder) decode(value reflect.Value, rec Record) error {
+	for _, decField := range dec.fields {
+		raw, err := decField.get(rec)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", decField.name, err)
+		}
+		if err := decField.set(value.Field(decField.index), raw); err != nil {
+			return fmt.Errorf("field %s: %w", decField.name, err)
+		}
+	}
+
+	return nil
+}
+
+// get - get raw value of the field from the record
+func (decField decoderField) get(rec Record) (string, error) {
+	switch {
+	case decField.number < 0:
+		return rec.Get(decField.column)
+	case decField.number == 0:
+		return rec.Line, nil
+	case decField.number > len(rec.Fields):
+		return "", fmt.Errorf("%w: $%d", ErrMissingField, decField.number)
+	default:
+		return rec.Fields[decField.number-1], nil
+	}
+}
+
+// set - convert raw value and set it to the struct field
+func (decField decoderField) set(field reflect.Value, raw string) error {
+	if field.Type() == timeType {
+		result, err := time.Parse(decField.layout, strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(result))
+		return nil
+	}
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	if field.Kind() == reflect.String {
+		field.SetString(raw)
+		return nil
+	}
+
+	raw = strings.TrimSpace(raw)
+	switch field.Kind() {
+	case reflect.Bool:
+		result, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(result)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(result)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(result)
+	case reflect.Float32, reflect.Float64:
+		result, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(result)
+	}
+
+	return nil
+}
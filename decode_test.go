@@ -0,0 +1,171 @@
+package byline_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func TestDecodeByNumbers(t *testing.T) {
+	type row struct {
+		Line    string    `byline:"0"`
+		ID      uint16    `byline:"1"`
+		Name    string    `byline:"2"`
+		Price   float64   `byline:"3"`
+		Count   int       `byline:"4"`
+		Active  bool      `byline:"5"`
+		Date    time.Time `byline:"6" layout:"2006-01-02"`
+		Level   level     `byline:"7"`
+		Skipped string    `byline:"-"`
+		NoTag   string
+	}
+
+	reader := strings.NewReader("1,one,12.5,-3,true,2024-01-02,low\n2,two,7,10,0,2024-02-03,high\n")
+
+	rows := []row{}
+	result, err := byline.Decode(byline.NewReader(reader).SetFS(regexp.MustCompile(`,`)), func(rec row, vars byline.AWKVars) (string, error) {
+		rows = append(rows, rec)
+		return strconv.Itoa(vars.NR), nil
+	}).ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "1\n2\n", result)
+	require.Equal(t, []row{
+		{
+			Line:   "1,one,12.5,-3,true,2024-01-02,low",
+			ID:     1,
+			Name:   "one",
+			Price:  12.5,
+			Count:  -3,
+			Active: true,
+			Date:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Level:  1,
+		},
+		{
+			Line:   "2,two,7,10,0,2024-02-03,high",
+			ID:     2,
+			Name:   "two",
+			Price:  7,
+			Count:  10,
+			Active: false,
+			Date:   time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC),
+			Level:  2,
+		},
+	}, rows)
+}
+
+func TestDecodeByNames(t *testing.T) {
+	type row struct {
+		Name  string  `byline:"name"`
+		Price float64 `byline:"price"`
+		First string  `byline:"1"`
+	}
+
+	reader := strings.NewReader("id,price,name\nA1,12.5,one\nA2,7.25,two\n")
+
+	result, err := byline.Decode(byline.NewReader(reader).SetCSV(byline.CSVOptions{}), func(rec row, vars byline.AWKVars) (string, error) {
+		return fmt.Sprintf("%s/%s/%.2f", rec.First, rec.Name, rec.Price), nil
+	}).ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "A1/one/12.50\nA2/two/7.25\n", result)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	t.Run("conversion error", func(t *testing.T) {
+		type row struct {
+			ID int `byline:"1"`
+		}
+
+		err := byline.Decode(byline.NewReader(strings.NewReader("1\n2\nx\n")), func(rec row, vars byline.AWKVars) (string, error) {
+			return "", nil
+		}).Discard()
+
+		var lineErr *byline.LineError
+		require.True(t, errors.As(err, &lineErr))
+		require.Equal(t, 3, lineErr.NR)
+		require.ErrorIs(t, err, strconv.ErrSyntax)
+		require.Equal(t, `line 3: field ID: strconv.ParseInt: parsing "x": invalid syntax`, err.Error())
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		type row struct {
+			Name string `byline:"2"`
+		}
+
+		err := byline.Decode(byline.NewReader(strings.NewReader("1 a\n2\n")), func(rec row, vars byline.AWKVars) (string, error) {
+			return "", nil
+		}).Discard()
+		require.ErrorIs(t, err, byline.ErrMissingField)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		type row struct {
+			Name string `byline:"name"`
+		}
+
+		err := byline.Decode(byline.NewReader(strings.NewReader("id title\n1 a\n")), func(rec row, vars byline.AWKVars) (string, error) {
+			return "", nil
+		}).Discard()
+		require.ErrorIs(t, err, byline.ErrUnknownColumn)
+	})
+
+	t.Run("TextUnmarshaler error", func(t *testing.T) {
+		type row struct {
+			Level level `byline:"1"`
+		}
+
+		err := byline.Decode(byline.NewReader(strings.NewReader("low\nmiddle\n")), func(rec row, vars byline.AWKVars) (string, error) {
+			return "", nil
+		}).Discard()
+		require.Error(t, err)
+		require.Equal(t, `line 2: field Level: unknown level "middle"`, err.Error())
+	})
+
+	t.Run("unsupported types", func(t *testing.T) {
+		type row struct {
+			Names []string `byline:"1"`
+		}
+
+		// invalid type is reported on Decode call, even for empty input
+		require.PanicsWithError(t, "decode: field Names has unsupported type []string", func() {
+			byline.Decode(byline.NewReader(strings.NewReader("")), func(rec row, vars byline.AWKVars) (string, error) {
+				return "", nil
+			})
+		})
+
+		require.PanicsWithError(t, "decode: int is not a struct", func() {
+			byline.Decode(byline.NewReader(strings.NewReader("")).SkipErrors(-1), func(rec int, vars byline.AWKVars) (string, error) {
+				return "", nil
+			})
+		})
+
+		type unexported struct {
+			id int `byline:"1"`
+		}
+		require.PanicsWithError(t, "decode: field id is unexported", func() {
+			byline.Decode(byline.NewReader(strings.NewReader("1\n")), func(rec unexported, vars byline.AWKVars) (string, error) {
+				return strconv.Itoa(rec.id), nil
+			})
+		})
+	})
+}
@@ -22,6 +22,9 @@ Usage
 	// Use everywhere instead of io.Reader
 	_, err := io.Copy(os.Stdout, lr)
 
+	// Read several inputs sequentially, FILENAME and FNR are available in AWK mode
+	lr := byline.NewMultiReader(byline.NamedReader{Name: "a.txt", Reader: fileA}, byline.NamedReader{Name: "b.txt", Reader: fileB})
+
 	// Or in one place
 	result, err := byline.NewReader(reader).MapString(func(line string) string {return "prefix_" + line}).ReadAll()
 */
@@ -0,0 +1,73 @@
+package byline
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyErrors - error for exceeding the number of errors in SkipErrors mode
+var ErrTooManyErrors = errors.New("too many errors")
+
+// LineError - error from filter function with the line number and the original line
+type LineError struct {
+	NR          int    // number of the line (begin from 1)
+	Line        []byte // original line before all filters
+	FilterIndex int    // index of the failed filter function in the Reader filters chain
+	Err         error  // error returned by the filter function
+}
+
+// Error - implement error interface
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.NR, e.Err)
+}
+
+// Unwrap - get the original error returned by the filter function, for errors.Is/As
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// lineError - wrap error from filter function, returns nil if line with error must be skipped
+func (lr *Reader) lineError(filterIndex int, err error) error {
+	// filter functions of addresses are internal
+	for _, addr := range lr.addrs {
+		if addr.index < filterIndex {
+			filterIndex--
+		}
+	}
+
+	lineErr := &LineError{
+		NR:          lr.awkVars.NR,
+		Line:        append([]byte{}, lr.line...),
+		FilterIndex: filterIndex,
+		Err:         err,
+	}
+	if lr.maxErrors == 0 {
+		return lineErr
+	}
+
+	lr.errs = append(lr.errs, lineErr)
+	if lr.maxErrors > 0 && len(lr.errs) > lr.maxErrors {
+		return ErrTooManyErrors
+	}
+	return nil
+}
+
+// SkipErrors - skip lines with errors from filter functions instead of stopping reading,
+// errors are collected and available via Errors().
+// If the number of errors exceeds maxErrors, Read returns ErrTooManyErrors, maxErrors < 0 - unlimited,
+// maxErrors == 0 - default mode, the first error stops reading.
+func (lr *Reader) SkipErrors(maxErrors int) *Reader {
+	if lr == nil {
+		return nil
+	}
+	lr.maxErrors = maxErrors
+	return lr
+}
+
+// Errors - get errors collected in SkipErrors mode
+func (lr *Reader) Errors() []*LineError {
+	if lr == nil {
+		return nil
+	}
+	return lr.errs
+}
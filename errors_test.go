@@ -0,0 +1,99 @@
+package byline_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineError(t *testing.T) {
+	errBadRow := errors.New("bad row")
+
+	reader := strings.NewReader("1\n2\nbad\n4\n")
+	_, err := byline.NewReader(reader).
+		Each(func(line []byte) {
+			line[0] = 'Z'
+		}).
+		MapErr(func(line []byte) ([]byte, error) {
+			if strings.HasPrefix(string(line), "Zad") {
+				return line, errBadRow
+			}
+			return line, nil
+		}).
+		ReadAll()
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errBadRow)
+	require.Equal(t, "line 3: bad row", err.Error())
+
+	var lineErr *byline.LineError
+	require.True(t, errors.As(err, &lineErr))
+	require.Equal(t, 3, lineErr.NR)
+	require.Equal(t, "bad\n", string(lineErr.Line))
+	require.Equal(t, 1, lineErr.FilterIndex)
+}
+
+func TestLineErrorNotForEOFAndOmit(t *testing.T) {
+	reader := strings.NewReader("1\n2\n3\n4\n")
+	result, err := byline.NewReader(reader).
+		MapStringErr(func(line string) (string, error) {
+			switch line {
+			case "2\n":
+				return "", byline.ErrOmitLine
+			case "3\n":
+				return line, io.EOF
+			}
+			return line, nil
+		}).
+		ReadAllString()
+
+	require.NoError(t, err)
+	require.Equal(t, "1\n3\n", result)
+}
+
+func TestSkipErrors(t *testing.T) {
+	filter := func(line string) (string, error) {
+		if strings.HasPrefix(line, "bad") {
+			return "", errors.New("bad row")
+		}
+		return line, nil
+	}
+
+	t.Run("unlimited", func(t *testing.T) {
+		lr := byline.NewReader(strings.NewReader("1\nbad 2\n3\nbad 4\n5\n")).
+			SkipErrors(-1).
+			MapStringErr(filter)
+
+		result, err := lr.ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "1\n3\n5\n", result)
+
+		errs := lr.Errors()
+		require.Len(t, errs, 2)
+		require.Equal(t, 2, errs[0].NR)
+		require.Equal(t, "bad 2\n", string(errs[0].Line))
+		require.Equal(t, 4, errs[1].NR)
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		lr := byline.NewReader(strings.NewReader("1\nbad 2\n3\nbad 4\nbad 5\n6\n")).
+			SkipErrors(1).
+			MapStringErr(filter)
+
+		err := lr.Discard()
+		require.ErrorIs(t, err, byline.ErrTooManyErrors)
+		require.Len(t, lr.Errors(), 2)
+	})
+
+	t.Run("default mode", func(t *testing.T) {
+		lr := byline.NewReader(strings.NewReader("1\nbad 2\n3\n")).MapStringErr(filter)
+
+		err := lr.Discard()
+		require.Error(t, err)
+		require.Empty(t, lr.Errors())
+	})
+}
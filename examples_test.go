@@ -166,12 +166,43 @@ func ExampleReader_Grep() {
 	// 	existsData  bool
 	// 	filterFuncs []func(line []byte) ([]byte, error)
 	// 	awkVars     AWKVars
+	// 	rs          []byte
+	// 	rsRe        *regexp.Regexp
+	// 	rt          []byte
+	// 	paragraph   bool
+	// 	skipNL      bool
+	// 	maxLineSize int
+	// 	longLines   LongLinePolicy
+	// 	truncated   []byte
+	// 	chunk       []byte
+	// 	line        []byte
+	// 	maxErrors   int
+	// 	errs        []*LineError
+	// 	beginFuncs  []func() string
+	// 	endFuncs    []func(vars AWKVars) string
+	// 	begun       bool
+	// 	ended       bool
+	// 	inputs      []NamedReader
+	// 	csv         *CSVOptions
+	// 	widths      []int
+	// 	fpat        *regexp.Regexp
+	// 	ctx         context.Context
+	// 	stages      []*parallelStage
+	// 	token       []byte
+	// 	ahead       *lookahead
+	// 	addrs       []*address
+	// 	scope       *address
 	// }
 	// type AWKVars struct {
-	// 	NR int
-	// 	NF int
-	// 	RS byte
-	// 	FS *regexp.Regexp
+	// 	NR       int
+	// 	FNR      int
+	// 	NF       int
+	// 	RS       byte
+	// 	FS       *regexp.Regexp
+	// 	RT       string
+	// 	OFS      string
+	// 	ORS      string
+	// 	FILENAME string
 	// }
 }
 
@@ -264,3 +295,56 @@ func ExampleReader_EachString() {
 	}
 	// Output: ["111\n" "222\n" "333\n"]
 }
+
+func ExampleReader_End() {
+	reader := strings.NewReader(`A001,name one,12.3
+A002,second row,7.1
+A003,three row,15.51
+`)
+
+	sum := 0.0
+	result, err := byline.NewReader(reader).
+		SetFS(regexp.MustCompile(`,`)).
+		Begin(func() string {
+			return "Report:\n"
+		}).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			price, err := strconv.ParseFloat(fields[2], 64)
+			sum += price
+			return fmt.Sprintf("%d. %s - %s", vars.NR, fields[0], fields[1]), err
+		}).
+		End(func(vars byline.AWKVars) string {
+			return fmt.Sprintf("Sum: %.2f (%d lines)\n", sum, vars.NR)
+		}).
+		ReadAllString()
+
+	fmt.Print(result, err)
+	// Output:
+	// Report:
+	// 1. A001 - name one
+	// 2. A002 - second row
+	// 3. A003 - three row
+	// Sum: 34.91 (3 lines)
+	// <nil>
+}
+
+func ExampleReader_Strings() {
+	reader := strings.NewReader("1 one\n2 two\n3 three\n4 four\n")
+
+	lr := byline.NewReader(reader).AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+		return fields[1], nil
+	})
+	for line, err := range lr.Strings() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if line == "three\n" {
+			break
+		}
+		fmt.Print(line)
+	}
+	// Output:
+	// one
+	// two
+}
@@ -0,0 +1,67 @@
+package byline
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// SetFieldWidths - set fixed widths of fields (in characters) for AWK mode instead of FS, like FIELDWIDTHS in GNU awk.
+// The rest of line after the last field is ignored, for short lines the last field is shorter and the next fields are omitted.
+// Widths must be positive, otherwise the call is ignored.
+func (lr *Reader) SetFieldWidths(widths ...int) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if len(widths) == 0 {
+		return lr
+	}
+	for _, width := range widths {
+		if width <= 0 {
+			return lr
+		}
+	}
+
+	lr.resetFieldsMode()
+	lr.widths = append([]int{}, widths...)
+	return lr
+}
+
+// SetFPAT - set regexp for fields content for AWK mode instead of FS, like FPAT in GNU awk:
+// fields are matches of the regexp, not the text between separators.
+func (lr *Reader) SetFPAT(fpat *regexp.Regexp) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if fpat == nil {
+		return lr
+	}
+
+	lr.resetFieldsMode()
+	lr.fpat = fpat
+	return lr
+}
+
+// resetFieldsMode - reset fields splitting to the default FS mode
+func (lr *Reader) resetFieldsMode() {
+	lr.csv, lr.widths, lr.fpat = nil, nil, nil
+}
+
+// splitByWidths - split line to fields by fixed widths
+func splitByWidths(line string, widths []int) []string {
+	fields := make([]string, 0, len(widths))
+	for _, width := range widths {
+		if line == "" {
+			break
+		}
+
+		end := 0
+		for count := 0; count < width && end < len(line); count++ {
+			_, size := utf8.DecodeRuneInString(line[end:])
+			end += size
+		}
+		fields = append(fields, line[:end])
+		line = line[end:]
+	}
+
+	return fields
+}
@@ -0,0 +1,75 @@
+package byline_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFieldWidths(t *testing.T) {
+	reader := strings.NewReader("A001  name one  12.30\nA002  тест       7.10 tail\nA003  short\nA0\n\n")
+
+	result := [][]string{}
+	err := byline.NewReader(reader).
+		SetFieldWidths(4, 2, 10, 5).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			require.Equal(t, len(fields), vars.NF)
+			result = append(result, fields)
+			return line, nil
+		}).Discard()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"A001", "  ", "name one  ", "12.30"},
+		{"A002", "  ", "тест      ", " 7.10"},
+		{"A003", "  ", "short"},
+		{"A0"},
+		{},
+	}, result)
+}
+
+func TestSetFieldWidthsInvalid(t *testing.T) {
+	result := [][]string{}
+	err := byline.NewReader(strings.NewReader("a b c\n")).
+		SetFieldWidths(1, 0, 2).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			result = append(result, fields)
+			return line, nil
+		}).Discard()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b", "c"}}, result)
+}
+
+func TestSetFSResetsFieldWidths(t *testing.T) {
+	result := [][]string{}
+	err := byline.NewReader(strings.NewReader("a,b,c\n")).
+		SetFieldWidths(1, 1).
+		SetFS(regexp.MustCompile(`,`)).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			result = append(result, fields)
+			return line, nil
+		}).Discard()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b", "c"}}, result)
+}
+
+func TestSetFPAT(t *testing.T) {
+	reader := strings.NewReader(`127.0.0.1 - "GET /index.html HTTP/1.1" 200` + "\n" + `10.0.0.1 "quoted \"x\"" 404` + "\n\n")
+
+	result := [][]string{}
+	err := byline.NewReader(reader).
+		SetFPAT(regexp.MustCompile(`"(?:[^"\\]|\\.)*"|[^\s"]+`)).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			require.Equal(t, len(fields), vars.NF)
+			result = append(result, fields)
+			return line, nil
+		}).Discard()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"127.0.0.1", "-", `"GET /index.html HTTP/1.1"`, "200"},
+		{"10.0.0.1", `"quoted \"x\""`, "404"},
+		{},
+	}, result)
+}
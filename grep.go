@@ -0,0 +1,214 @@
+package byline
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GrepFixed - grep lines which contain fixed string, like grep -F
+func (lr *Reader) GrepFixed(pattern []byte) *Reader {
+	return lr.Grep(func(line []byte) bool {
+		return bytes.Contains(line, pattern)
+	})
+}
+
+// GrepFixedFold - grep lines which contain fixed string with case-insensitive matching
+// by Unicode simple case folding (like bytes.EqualFold), like grep -F -i
+func (lr *Reader) GrepFixedFold(pattern []byte) *Reader {
+	if !hasFoldableRunes(pattern) {
+		return lr.GrepFixed(pattern)
+	}
+
+	runes := bytes.Runes(pattern)
+	return lr.Grep(func(line []byte) bool {
+		return containsFold(line, runes)
+	})
+}
+
+// errStopAfterLine - stop reading after the line processed by all filter functions, see GrepMax
+var errStopAfterLine = errors.New("stop after line")
+
+// GrepV - grep lines which are not matched by func, like grep -v
+func (lr *Reader) GrepV(filterFn func([]byte) bool) *Reader {
+	return lr.Grep(func(line []byte) bool {
+		return !filterFn(line)
+	})
+}
+
+// GrepMax - grep lines by func and stop reading after n matched lines, like grep -m
+func (lr *Reader) GrepMax(n int, filterFn func([]byte) bool) *Reader {
+	if lr == nil {
+		return nil
+	}
+
+	matched := 0
+	return lr.MapErr(func(line []byte) ([]byte, error) {
+		if n <= 0 {
+			return nullBytes, io.EOF
+		}
+		if !filterFn(line) {
+			return nullBytes, ErrOmitLine
+		}
+
+		if matched++; matched >= n {
+			// the next filters get this line
+			return line, errStopAfterLine
+		}
+		return line, nil
+	})
+}
+
+// GrepCount - read all content from Reader and get the number of lines matched by func, like grep -c
+func (lr *Reader) GrepCount(filterFn func([]byte) bool) (int, error) {
+	count := 0
+	err := lr.Grep(filterFn).Map(func(line []byte) []byte {
+		count++
+		return nullBytes
+	}).Discard()
+
+	return count, err
+}
+
+// GrepContext - grep lines by regexp with context lines like grep -B/-A/-C:
+// before lines before and after lines after each matched line.
+// The lines before are merged with the matched line into one record (with NR of the matched line),
+// so the next filter functions and ReadAllSlice* get them as one line, GrepContext must be the last filter
+func (lr *Reader) GrepContext(re *regexp.Regexp, before, after int) *Reader {
+	return lr.GrepContextSep(re, before, after, nil)
+}
+
+// GrepContextSep - like GrepContext, but sep is inserted between non-contiguous groups of lines
+// (like "--" in grep), sep is emitted as is and must be terminated by the record separator,
+// sep is merged with the next record like the lines before
+func (lr *Reader) GrepContextSep(re *regexp.Regexp, before, after int, sep []byte) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if before < 0 {
+		before = 0
+	}
+
+	var (
+		ring      = make([][]byte, before) // the previous not emitted lines
+		ringStart int                      // index of the oldest line in the ring
+		ringLen   int
+		num       int // number of the line in this filter
+		lastNum   int // number of the last emitted line
+		afterLeft int // number of lines for the trailing context
+	)
+
+	return lr.MapErr(func(line []byte) ([]byte, error) {
+		num++
+		body, _ := lr.trimRS(line)
+
+		if !re.Match(body) {
+			if afterLeft > 0 {
+				afterLeft--
+				lastNum = num
+				return line, nil
+			}
+			if before > 0 {
+				// save the copy of the line, the line buffer is reused by the scanner
+				i := (ringStart + ringLen) % before
+				if ringLen == before {
+					ringStart = (ringStart + 1) % before
+				} else {
+					ringLen++
+				}
+				ring[i] = append(ring[i][:0], line...)
+			}
+			return nullBytes, ErrOmitLine
+		}
+
+		afterLeft = after
+		gap := lastNum > 0 && num-ringLen > lastNum+1
+		if !gap && ringLen == 0 {
+			lastNum = num
+			return line, nil
+		}
+
+		var result []byte
+		if gap {
+			result = append(result, sep...)
+		}
+		for i := 0; i < ringLen; i++ {
+			result = append(result, ring[(ringStart+i)%before]...)
+		}
+		ringLen, lastNum = 0, num
+		return append(result, line...), nil
+	})
+}
+
+// GrepAnyOf - grep lines which contain any of patterns, like grep -F with many patterns,
+// patterns are matched by Aho-Corasick automaton in one pass over the line
+func (lr *Reader) GrepAnyOf(patterns [][]byte) *Reader {
+	return lr.GrepAnyOfOptions(patterns, AnyOfOptions{})
+}
+
+// GrepAnyOfOptions - like GrepAnyOf, with options for whole words and case-insensitive matching
+func (lr *Reader) GrepAnyOfOptions(patterns [][]byte, opts AnyOfOptions) *Reader {
+	if lr == nil {
+		return nil
+	}
+
+	ac := newAhoCorasick(patterns, opts)
+	return lr.Grep(func(line []byte) bool {
+		body, _ := lr.trimRS(line)
+		return ac.match(body)
+	})
+}
+
+// containsFold - the line contains the pattern with case folding
+func containsFold(line []byte, pattern []rune) bool {
+	for start := 0; start < len(line); {
+		if hasPrefixFold(line[start:], pattern) {
+			return true
+		}
+		_, size := utf8.DecodeRune(line[start:])
+		start += size
+	}
+	return len(pattern) == 0
+}
+
+// hasPrefixFold - the line begins with the pattern with case folding,
+// the lengths in bytes can be different for folded runes
+func hasPrefixFold(line []byte, pattern []rune) bool {
+	for _, pr := range pattern {
+		if len(line) == 0 {
+			return false
+		}
+		r, size := utf8.DecodeRune(line)
+		if !equalFoldRune(r, pr) {
+			return false
+		}
+		line = line[size:]
+	}
+	return true
+}
+
+// equalFoldRune - runes are equal with Unicode simple case folding
+func equalFoldRune(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFoldableRunes - the pattern contains runes which have other cases
+func hasFoldableRunes(pattern []byte) bool {
+	for _, r := range string(pattern) {
+		if unicode.SimpleFold(r) != r {
+			return true
+		}
+	}
+	return false
+}
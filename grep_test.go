@@ -0,0 +1,207 @@
+package byline_test
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepContext(t *testing.T) {
+	in := "1\n2\nerr 3\n4\n5\n6\n7\nerr 8\n9\nerr 10\n11\n12"
+	re := regexp.MustCompile(`^err`)
+
+	tests := []struct {
+		name          string
+		before, after int
+		sep           string
+		out           string
+	}{
+		{name: "without context", out: "err 3\nerr 8\nerr 10\n"},
+		{name: "before", before: 1, out: "2\nerr 3\n7\nerr 8\n9\nerr 10\n"},
+		{name: "after", after: 1, out: "err 3\n4\nerr 8\n9\nerr 10\n11\n"},
+		{name: "before and after with sep", before: 1, after: 1, sep: "--\n", out: "2\nerr 3\n4\n--\n7\nerr 8\n9\nerr 10\n11\n"},
+		{name: "contiguous groups", before: 2, after: 2, sep: "--\n", out: "1\n2\nerr 3\n4\n5\n6\n7\nerr 8\n9\nerr 10\n11\n12"},
+		{name: "sep without context", sep: "--\n", out: "err 3\n--\nerr 8\n--\nerr 10\n"},
+		{name: "large before", before: 10, out: "1\n2\nerr 3\n4\n5\n6\n7\nerr 8\n9\nerr 10\n"},
+		{name: "negative before", before: -1, after: 0, out: "err 3\nerr 8\nerr 10\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := byline.NewReader(strings.NewReader(in)).
+				GrepContextSep(re, tt.before, tt.after, []byte(tt.sep)).
+				ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, tt.out, result)
+		})
+	}
+}
+
+func TestGrepContextMatchWithoutRS(t *testing.T) {
+	result, err := byline.NewReader(strings.NewReader("a\nb\nc\n")).
+		GrepContext(regexp.MustCompile(`^b$`), 1, 0).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "a\nb\n", result)
+}
+
+func TestGrepV(t *testing.T) {
+	result, err := byline.NewReader(strings.NewReader("# comment\nline 1\n# comment\nline 2\n")).
+		GrepV(func(line []byte) bool { return strings.HasPrefix(string(line), "#") }).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "line 1\nline 2\n", result)
+}
+
+type countingReader struct {
+	reader io.Reader
+	reads  int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	return r.reader.Read(p[:1])
+}
+
+func TestGrepMax(t *testing.T) {
+	isEven := func(line []byte) bool {
+		num, err := strconv.Atoi(strings.TrimSpace(string(line)))
+		return err == nil && num%2 == 0
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		out  string
+	}{
+		{name: "one", n: 1, out: "2\n"},
+		{name: "two", n: 2, out: "2\n4\n"},
+		{name: "more than matches", n: 10, out: "2\n4\n6\n"},
+		{name: "zero", n: 0, out: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &countingReader{reader: strings.NewReader("1\n2\n3\n4\n5\n6\n7\n")}
+			result, err := byline.NewReader(reader).GrepMax(tt.n, isEven).ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, tt.out, result)
+			if tt.n == 1 {
+				// reads by one byte, the rest of input is not read
+				require.Less(t, reader.reads, 6)
+			}
+		})
+	}
+}
+
+func TestGrepCount(t *testing.T) {
+	count, err := byline.NewReader(strings.NewReader("a\nb\nab\nc")).
+		GrepCount(func(line []byte) bool { return strings.Contains(string(line), "a") })
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	var lr *byline.Reader
+	_, err = lr.GrepCount(func([]byte) bool { return true })
+	require.Equal(t, byline.ErrNilReader, err)
+}
+
+func TestGrepFixed(t *testing.T) {
+	in := "Error: disk\nerror: net\nwarning\nΣΊΣΥΦΟΣ\nkelvin K\n"
+
+	tests := []struct {
+		name    string
+		pattern string
+		fold    bool
+		out     string
+	}{
+		{name: "fixed", pattern: "error", out: "error: net\n"},
+		{name: "fixed with regexp chars", pattern: "r: n", out: "error: net\n"},
+		{name: "fixed not found", pattern: "fatal", out: ""},
+		{name: "fixed empty", pattern: "", out: in},
+		{name: "fold", pattern: "ERROR", fold: true, out: "Error: disk\nerror: net\n"},
+		{name: "fold unicode", pattern: "σίσυφος", fold: true, out: "ΣΊΣΥΦΟΣ\n"},
+		{name: "fold different length", pattern: "\u212aELVIN \u212a", fold: true, out: "kelvin K\n"},
+		{name: "fold without letters", pattern: ": ", fold: true, out: "Error: disk\nerror: net\n"},
+		{name: "fold empty", pattern: "", fold: true, out: in},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := byline.NewReader(strings.NewReader(in))
+			if tt.fold {
+				lr.GrepFixedFold([]byte(tt.pattern))
+			} else {
+				lr.GrepFixed([]byte(tt.pattern))
+			}
+			result, err := lr.ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, tt.out, result)
+		})
+	}
+}
+
+func TestGrepByRegexpLiteral(t *testing.T) {
+	in := "a.b\naxb\nab\n"
+
+	for _, expr := range []string{`a\.b`, `a.b`, `ab`, `^ab`, `(?i)AB`, ``} {
+		re := regexp.MustCompile(expr)
+		result, err := byline.NewReader(strings.NewReader(in)).GrepByRegexp(re).ReadAllSliceString()
+		require.NoError(t, err)
+
+		var expected []string
+		for _, line := range strings.SplitAfter(in, "\n") {
+			if line != "" && re.MatchString(line) {
+				expected = append(expected, line)
+			}
+		}
+		require.Equal(t, expected, result, expr)
+	}
+}
+
+func TestGrepContextRecords(t *testing.T) {
+	var nrs []int
+	result, err := byline.NewReader(strings.NewReader("1\n2\nx\n3\n4\n5\nx\n6\n")).
+		GrepContextSep(regexp.MustCompile(`^x$`), 1, 1, []byte("--\n")).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			nrs = append(nrs, vars.NR)
+			return line, nil
+		}).
+		ReadAllSliceString()
+	require.NoError(t, err)
+	// the lines before and the separator are merged with the matched line
+	require.Equal(t, []string{"2\nx\n", "3\n", "--\n5\nx\n", "6\n"}, result)
+	require.Equal(t, []int{3, 4, 7, 8}, nrs)
+}
+
+func TestGrepMaxNextFilters(t *testing.T) {
+	startsWithA := func(line []byte) bool { return strings.HasPrefix(string(line), "a") }
+	in := "a1\nb\na2\na3\n"
+
+	lines, err := byline.NewReader(strings.NewReader(in)).GrepMax(2, startsWithA).ReadAllSliceString()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a1\n", "a2\n"}, lines)
+
+	result, err := byline.NewReader(strings.NewReader(in)).
+		GrepMax(2, startsWithA).
+		MapString(strings.ToUpper).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "A1\nA2\n", result)
+
+	count, err := byline.NewReader(strings.NewReader(in)).GrepMax(2, startsWithA).GrepCount(startsWithA)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	// the last line is omitted by the next filter, but reading is stopped
+	result, err = byline.NewReader(strings.NewReader(in)).
+		GrepMax(2, startsWithA).
+		GrepString(func(line string) bool { return line != "a2\n" }).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "a1\n", result)
+}
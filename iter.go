@@ -0,0 +1,42 @@
+package byline
+
+import (
+	"io"
+	"iter"
+)
+
+// Lines - get iterator over processed lines (with record separator), breaking the loop stops reading.
+// The line is valid only until the next iteration, an error stops the iteration. Do not mix it with Read.
+func (lr *Reader) Lines() iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		if lr == nil {
+			yield(nil, ErrNilReader)
+			return
+		}
+
+		for {
+			line, ok, err := lr.next()
+			if err == io.EOF {
+				return
+			}
+			if !ok && err == nil {
+				// omitted line
+				continue
+			}
+			if !yield(line, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Strings - get iterator over processed lines as strings, breaking the loop stops reading
+func (lr *Reader) Strings() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for line, err := range lr.Lines() {
+			if !yield(string(line), err) {
+				return
+			}
+		}
+	}
+}
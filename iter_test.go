@@ -0,0 +1,93 @@
+package byline_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+// lineByLineReader - returns one line per Read call
+type lineByLineReader struct {
+	lines []string
+	reads int
+}
+
+func (r *lineByLineReader) Read(p []byte) (int, error) {
+	if len(r.lines) == 0 {
+		return 0, io.EOF
+	}
+	r.reads++
+	n := copy(p, r.lines[0])
+	r.lines = r.lines[1:]
+	return n, nil
+}
+
+func TestLines(t *testing.T) {
+	t.Run("all lines", func(t *testing.T) {
+		lr := byline.NewReader(strings.NewReader("1\n2\n3\n4")).
+			GrepString(func(line string) bool { return line != "2\n" }).
+			Begin(func() string { return "begin\n" }).
+			End(func(vars byline.AWKVars) string { return "end\n" })
+
+		result := []string{}
+		for line, err := range lr.Lines() {
+			require.NoError(t, err)
+			result = append(result, string(line))
+		}
+		require.Equal(t, []string{"begin\n", "1\n", "3\n", "4", "end\n"}, result)
+	})
+
+	t.Run("empty lines are not omitted", func(t *testing.T) {
+		result := []string{}
+		for line, err := range byline.NewReader(strings.NewReader("1\n2\n")).MapString(func(string) string { return "" }).Strings() {
+			require.NoError(t, err)
+			result = append(result, line)
+		}
+		require.Equal(t, []string{"", ""}, result)
+	})
+
+	t.Run("break stops reading", func(t *testing.T) {
+		reader := &lineByLineReader{lines: []string{"1\n", "2\n", "3\n", "4\n", "5\n"}}
+
+		result := []string{}
+		for line, err := range byline.NewReader(reader).Strings() {
+			require.NoError(t, err)
+			result = append(result, line)
+			if len(result) == 2 {
+				break
+			}
+		}
+		require.Equal(t, []string{"1\n", "2\n"}, result)
+		require.Equal(t, 2, reader.reads)
+	})
+
+	t.Run("error stops iteration", func(t *testing.T) {
+		errBad := errors.New("bad")
+		lr := byline.NewReader(strings.NewReader("1\n2\n3\n")).MapStringErr(func(line string) (string, error) {
+			if line == "2\n" {
+				return line, errBad
+			}
+			return line, nil
+		})
+
+		result := []string{}
+		var lastErr error
+		for line, err := range lr.Strings() {
+			result = append(result, line)
+			lastErr = err
+		}
+		require.Equal(t, []string{"1\n", "2\n"}, result)
+		require.ErrorIs(t, lastErr, errBad)
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		var lr *byline.Reader
+		for _, err := range lr.Lines() {
+			require.ErrorIs(t, err, byline.ErrNilReader)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package byline
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// LongLinePolicy - policy for lines longer than max line size, see SetMaxLineSize
+type LongLinePolicy int
+
+const (
+	// LongLineError - Read returns bufio.ErrTooLong error, default policy
+	LongLineError LongLinePolicy = iota
+	// LongLineTruncate - line is truncated to max line size, separator is kept
+	LongLineTruncate
+	// LongLineSplit - line is emitted in chunks of max line size
+	LongLineSplit
+)
+
+// NewReaderSize - get new line by line Reader with max line size, see SetMaxLineSize
+func NewReaderSize(reader io.Reader, maxLineSize int) *Reader {
+	return NewReader(reader).SetMaxLineSize(maxLineSize)
+}
+
+// SetMaxLineSize - set max size of line with separator, default is 64KB (bufio.MaxScanTokenSize).
+// Must be called before reading. What to do with longer lines is set by SetLongLinePolicy.
+func (lr *Reader) SetMaxLineSize(size int) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if size <= 0 {
+		return lr
+	}
+	lr.maxLineSize = size
+	lr.scanner.Buffer(nil, size)
+	return lr
+}
+
+// SetLongLinePolicy - set policy for lines longer than max line size: error (default), truncate or split to chunks
+func (lr *Reader) SetLongLinePolicy(policy LongLinePolicy) *Reader {
+	if lr == nil {
+		return nil
+	}
+	lr.longLines = policy
+	return lr
+}
+
+// cutSize - size of data which can be cut without breaking the multi-byte separator at the end
+func (lr *Reader) cutSize(data []byte) int {
+	size := len(data)
+	if lr.rsRe == nil {
+		size -= len(lr.rs) - 1
+	}
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// longLine - process the line which does not fit to max line size
+func (lr *Reader) longLine(data []byte) (advance int, token []byte, err error) {
+	size := lr.cutSize(data)
+	if size == 0 {
+		size = len(data)
+	}
+
+	switch lr.longLines {
+	case LongLineTruncate:
+		lr.truncated = append([]byte{}, data[:size]...)
+		return size, nil, nil
+	case LongLineSplit:
+		// the chunk is emitted after checking the separator right after it
+		lr.chunk = append([]byte{}, data[:size]...)
+		return size, nil, nil
+	default:
+		return 0, nil, bufio.ErrTooLong
+	}
+}
+
+// skipTruncated - skip the rest of truncated line, returns the line with separator at the end
+func (lr *Reader) skipTruncated(data []byte, start, end int, atEOF bool) (advance int, token []byte, err error) {
+	if end > 0 {
+		token = append(lr.truncated, data[start:end]...)
+		lr.rt = token[len(lr.truncated):]
+		lr.truncated = nil
+		return end, token, nil
+	}
+	if atEOF {
+		token, lr.truncated = lr.truncated, nil
+		return len(data), lr.terminateInput(token), nil
+	}
+
+	return lr.cutSize(data), nil, nil
+}
+
+// nextChunk - emit the chunk of split long line, with the separator if it is right after the chunk,
+// so the separator is not emitted as a separate empty line
+func (lr *Reader) nextChunk(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if lr.rsRe == nil && !atEOF && len(data) < len(lr.rs) && bytes.HasPrefix(lr.rs, data) {
+		// the beginning of multi-byte separator, request more data
+		return 0, nil, nil
+	}
+
+	start, end := lr.findRS(data)
+	if start == 0 && end > 0 {
+		if lr.rsRe != nil && end == len(data) && !atEOF && len(data) < lr.maxLineSize {
+			// match at the end of data, it can be longer after the next read
+			return 0, nil, nil
+		}
+		token, lr.chunk = append(lr.chunk, data[:end]...), nil
+		lr.rt = token[len(token)-end:]
+		return end, token, nil
+	}
+
+	token, lr.chunk, lr.rt = lr.chunk, nil, nil
+	if atEOF && len(data) == 0 {
+		token = lr.terminateInput(token)
+	}
+	return 0, token, nil
+}
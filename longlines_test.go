@@ -0,0 +1,106 @@
+package byline_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxLineSize(t *testing.T) {
+	t.Run("default size", func(t *testing.T) {
+		longLine := strings.Repeat("x", bufio.MaxScanTokenSize)
+		_, err := byline.NewReader(strings.NewReader("1\n" + longLine + "\n")).ReadAll()
+		require.ErrorIs(t, err, bufio.ErrTooLong)
+	})
+
+	t.Run("NewReaderSize", func(t *testing.T) {
+		longLine := strings.Repeat("x", bufio.MaxScanTokenSize*2) + "\n"
+		result, err := byline.NewReaderSize(strings.NewReader("1\n"+longLine+"2"), len(longLine)).ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{"1\n", longLine, "2"}, result)
+	})
+
+	t.Run("NewReaderSize with nil reader", func(t *testing.T) {
+		require.Nil(t, byline.NewReaderSize(nil, 10))
+	})
+
+	t.Run("small size with error", func(t *testing.T) {
+		_, err := byline.NewReaderSize(strings.NewReader("1234\n123456789\n"), 8).ReadAll()
+		require.ErrorIs(t, err, bufio.ErrTooLong)
+	})
+}
+
+func TestLongLinePolicy(t *testing.T) {
+	cases := []struct {
+		name   string
+		rs     string
+		policy byline.LongLinePolicy
+		in     string
+		out    []string
+	}{
+		{
+			name:   "truncate",
+			rs:     "\n",
+			policy: byline.LongLineTruncate,
+			in:     "1234\n12345678901234567890\nab\n",
+			out:    []string{"1234\n", "12345678\n", "ab\n"},
+		},
+		{
+			name:   "truncate last line",
+			rs:     "\n",
+			policy: byline.LongLineTruncate,
+			in:     "ab\n12345678901234567890",
+			out:    []string{"ab\n", "12345678"},
+		},
+		{
+			name:   "truncate with multi-byte separator",
+			rs:     "\r\n",
+			policy: byline.LongLineTruncate,
+			in:     "1234\r\n123456\r\n12345678901234567890\r\nab",
+			out:    []string{"1234\r\n", "123456\r\n", "1234567\r\n", "ab"},
+		},
+		{
+			name:   "split",
+			rs:     "\n",
+			policy: byline.LongLineSplit,
+			in:     "1234\n12345678901234567890\nab\n",
+			out:    []string{"1234\n", "12345678", "90123456", "7890\n", "ab\n"},
+		},
+		{
+			name:   "split with multi-byte separator",
+			rs:     "\r\n",
+			policy: byline.LongLineSplit,
+			in:     "123456\r\n1234567890\r\n",
+			out:    []string{"123456\r\n", "1234567", "890\r\n"},
+		},
+		{
+			name:   "split with separator right after the chunk",
+			rs:     "\n",
+			policy: byline.LongLineSplit,
+			in:     "01234567\nab\n0123456789abcdef\n",
+			out:    []string{"01234567\n", "ab\n", "01234567", "89abcdef\n"},
+		},
+		{
+			name:   "split with multi-byte separator right after the chunk",
+			rs:     "\r\n",
+			policy: byline.LongLineSplit,
+			in:     "0123456\r\nab\r\n01234567",
+			out:    []string{"0123456\r\n", "ab\r\n", "0123456", "7"},
+		},
+	}
+
+	for _, row := range cases {
+		t.Run(row.name, func(t *testing.T) {
+			result, err := byline.NewReader(strings.NewReader(row.in)).
+				SetRSString(row.rs).
+				SetMaxLineSize(8).
+				SetLongLinePolicy(row.policy).
+				ReadAllSliceString()
+			require.NoError(t, err)
+			require.Equal(t, row.out, result)
+		})
+	}
+}
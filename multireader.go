@@ -0,0 +1,32 @@
+package byline
+
+import (
+	"io"
+	"strings"
+)
+
+// NamedReader - named input for NewMultiReader
+type NamedReader struct {
+	Name   string    // name of the input, available in AWK mode as FILENAME
+	Reader io.Reader // input
+}
+
+// NewMultiReader - get new line by line Reader for reading several inputs sequentially.
+// In AWK mode NR is the line number across all inputs, FNR is the line number in the current input,
+// and FILENAME is the name of the current input. The last line of an input without separator is terminated
+// by RS (newline for regexp RS), so it is not merged with the next input. Returns nil if any of the readers is nil.
+func NewMultiReader(named ...NamedReader) *Reader {
+	for _, input := range named {
+		if input.Reader == nil {
+			return nil
+		}
+	}
+	if len(named) == 0 {
+		return NewReader(strings.NewReader(""))
+	}
+
+	lr := NewReader(named[0].Reader)
+	lr.awkVars.FILENAME = named[0].Name
+	lr.inputs = named[1:]
+	return lr
+}
@@ -0,0 +1,92 @@
+package byline_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMultiReader(t *testing.T) {
+	t.Run("AWK vars", func(t *testing.T) {
+		lr := byline.NewMultiReader(
+			byline.NamedReader{Name: "a.txt", Reader: strings.NewReader("a1\na2\n")},
+			byline.NamedReader{Name: "empty.txt", Reader: strings.NewReader("")},
+			byline.NamedReader{Name: "b.txt", Reader: strings.NewReader("b1\nb2\nb3")},
+			byline.NamedReader{Name: "c.txt", Reader: strings.NewReader("c1")},
+		)
+
+		result, err := lr.AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			return fmt.Sprintf("%s:%d:%d:%s", vars.FILENAME, vars.NR, vars.FNR, line), nil
+		}).ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"a.txt:1:1:a1\n",
+			"a.txt:2:2:a2\n",
+			"b.txt:3:1:b1\n",
+			"b.txt:4:2:b2\n",
+			"b.txt:5:3:b3\n",
+			"c.txt:6:1:c1",
+		}, result)
+	})
+
+	t.Run("paragraph mode for each input", func(t *testing.T) {
+		lr := byline.NewMultiReader(
+			byline.NamedReader{Name: "a", Reader: strings.NewReader("\n\na 1\na 2\n\n")},
+			byline.NamedReader{Name: "b", Reader: strings.NewReader("\nb 1\n")},
+		)
+
+		result, err := lr.ParagraphMode().ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{"a 1\na 2\n\n", "b 1\n"}, result)
+	})
+
+	t.Run("last line without separator", func(t *testing.T) {
+		lr := byline.NewMultiReader(
+			byline.NamedReader{Name: "a", Reader: strings.NewReader("a1\na2")},
+			byline.NamedReader{Name: "b", Reader: strings.NewReader("b1\n")},
+			byline.NamedReader{Name: "c", Reader: strings.NewReader("c1;c2")},
+			byline.NamedReader{Name: "d", Reader: strings.NewReader("d1")},
+		)
+
+		result, err := lr.AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			return fmt.Sprintf("%s:%d:%s", vars.FILENAME, vars.FNR, line), nil
+		}).ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "a:1:a1\na:2:a2\nb:1:b1\nc:1:c1;c2\nd:1:d1", result)
+	})
+
+	t.Run("last line without multi-byte separator", func(t *testing.T) {
+		lr := byline.NewMultiReader(
+			byline.NamedReader{Name: "a", Reader: strings.NewReader("a1\r\na2")},
+			byline.NamedReader{Name: "b", Reader: strings.NewReader("b1\r\n")},
+		)
+
+		result, err := lr.SetRSString("\r\n").ReadAllSliceString()
+		require.NoError(t, err)
+		require.Equal(t, []string{"a1\r\n", "a2\r\n", "b1\r\n"}, result)
+	})
+
+	t.Run("without inputs", func(t *testing.T) {
+		result, err := byline.NewMultiReader().ReadAllString()
+		require.NoError(t, err)
+		require.Equal(t, "", result)
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		lr := byline.NewMultiReader(byline.NamedReader{Name: "a", Reader: strings.NewReader("")}, byline.NamedReader{Name: "nil"})
+		require.Nil(t, lr)
+	})
+
+	t.Run("FNR is equal to NR for one input", func(t *testing.T) {
+		err := byline.NewReader(strings.NewReader("1\n2\n3\n")).
+			AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+				require.Equal(t, vars.NR, vars.FNR)
+				require.Equal(t, "", vars.FILENAME)
+				return line, nil
+			}).Discard()
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,333 @@
+package byline
+
+import (
+	"io"
+	"runtime"
+)
+
+// parallelStage - filter function processed in parallel by workers, see ParallelMap
+type parallelStage struct {
+	index    int // index of the filter function in filterFuncs
+	workers  int
+	filterFn func([]byte) ([]byte, error)
+	ordered  bool
+	sem      chan struct{}     // limits the number of running workers
+	queue    []*parallelJob    // jobs in order of lines, for ordered mode
+	results  chan *parallelJob // finished jobs, for unordered mode
+	inFlight int               // number of jobs in results, for unordered mode
+	done     bool              // no more lines from the upstream
+	err      error             // error from the upstream, returned after processing of all jobs
+	vars     *AWKVars          // AWK vars of the last read line, for the first stage only
+	scope    *address          // address of lines for processing, nil - all lines
+}
+
+// parallelJob - line processed by worker
+type parallelJob struct {
+	rec    *record
+	result []byte
+	err    error
+	done   chan struct{}
+}
+
+// record - line with the state of Reader for processing it out of reading order
+type record struct {
+	line    []byte
+	orig    []byte  // original line for errors
+	rt      []byte  // record terminator
+	vars    AWKVars // AWK vars of the line
+	err     error   // error from filter functions, returned in order of lines
+	last    bool    // line stopped reading by io.EOF from filter function
+	actives []bool  // states of addresses for the line
+}
+
+// ParallelMap - set filter function for process lines in parallel by workers (GOMAXPROCS if workers <= 0),
+// the order of lines is preserved. Filter functions before and after it are processed sequentially.
+// filterFn must be safe for concurrent use and can return ErrOmitLine, io.EOF or error,
+// the error is returned from Read as LineError with the line number in order of lines.
+func (lr *Reader) ParallelMap(workers int, filterFn func([]byte) ([]byte, error)) *Reader {
+	return lr.addParallelStage(workers, true, filterFn)
+}
+
+// ParallelMapUnordered - like ParallelMap, but lines are returned as soon as workers process them,
+// without waiting for the previous lines. The order of lines is not preserved,
+// but AWK vars (NR, FNR, FILENAME, ...) in the next filter functions and
+// the line number in LineError are related to the original line, so NR is the original line number.
+func (lr *Reader) ParallelMapUnordered(workers int, filterFn func([]byte) ([]byte, error)) *Reader {
+	return lr.addParallelStage(workers, false, filterFn)
+}
+
+// addParallelStage - add the filter function processed in parallel
+func (lr *Reader) addParallelStage(workers int, ordered bool, filterFn func([]byte) ([]byte, error)) *Reader {
+	if lr == nil {
+		return nil
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	stage := &parallelStage{
+		index:    len(lr.filterFuncs),
+		workers:  workers,
+		filterFn: filterFn,
+		ordered:  ordered,
+		scope:    lr.scope,
+		sem:      make(chan struct{}, workers),
+	}
+	if !ordered {
+		stage.results = make(chan *parallelJob, workers*2)
+	}
+	lr.stages = append(lr.stages, stage)
+	// the filter function is saved for the right indexes of the next filter functions
+	lr.filterFuncs = append(lr.filterFuncs, filterFn)
+	return lr
+}
+
+// nextParallel - get the next processed line from the last parallel stage and filter functions after it
+func (lr *Reader) nextParallel() (lineBytes []byte, ok bool, err error) {
+	last := len(lr.stages) - 1
+	for {
+		rec, err := lr.stageNext(last)
+		if err != nil {
+			if vars := lr.stages[0].vars; vars != nil {
+				// AWK vars of the last read line for End
+				lr.awkVars = *vars
+			}
+			return nil, false, err
+		}
+
+		lr.restore(rec)
+		if rec.err != nil {
+			return rec.line, true, rec.err
+		}
+
+		lineBytes, ok, err = lr.processFilters(rec.line, lr.stages[last].index+1, len(lr.filterFuncs))
+		if err == io.EOF || rec.last {
+			// stop reading, but return the rest of the buffer
+			lr.existsData = false
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if ok || err != nil || !lr.existsData {
+			return lineBytes, ok, err
+		}
+	}
+}
+
+// stageNext - get the next processed line from the parallel stage
+func (lr *Reader) stageNext(stageNum int) (*record, error) {
+	stage := lr.stages[stageNum]
+	for {
+		lr.fillStage(stageNum)
+		if stage.pending() == 0 {
+			if stage.err != nil {
+				return nil, stage.err
+			}
+			return nil, io.EOF
+		}
+
+		job := stage.pop()
+		rec := job.rec
+		if !stage.ordered {
+			// the last line from the upstream can be finished before the other lines
+			rec.last = false
+		}
+		if rec.err != nil {
+			// error from the upstream
+			return rec, nil
+		}
+
+		switch job.err {
+		case nil:
+			rec.line = job.result
+			return rec, nil
+		case ErrOmitLine:
+			if rec.last {
+				stage.stop()
+				return nil, io.EOF
+			}
+			continue
+		case io.EOF:
+			rec.line, rec.last = job.result, true
+			stage.stop()
+			return rec, nil
+		default:
+			lr.restore(rec)
+			if rec.err = lr.lineError(stage.index, job.err); rec.err == nil {
+				// skip line with error
+				continue
+			}
+			rec.line = job.result
+			return rec, nil
+		}
+	}
+}
+
+// fillStage - start processing of the next lines from the upstream up to the limit of lines in memory
+func (lr *Reader) fillStage(stageNum int) {
+	stage := lr.stages[stageNum]
+	for !stage.done && stage.pending() < stage.workers*2 {
+		rec, err := lr.pullRecord(stageNum)
+		if err != nil {
+			stage.done = true
+			if err != io.EOF {
+				stage.err = err
+			}
+			return
+		}
+
+		job := &parallelJob{rec: rec, done: make(chan struct{})}
+		if stage.ordered {
+			stage.queue = append(stage.queue, job)
+		} else {
+			stage.inFlight++
+		}
+		if rec.last {
+			stage.done = true
+		}
+
+		if rec.err != nil || stage.scope != nil && !stage.scope.active {
+			// the line out of the address is passed through untouched
+			job.result = rec.line
+			stage.finish(job)
+			continue
+		}
+		go stage.run(job)
+	}
+}
+
+// run - process the line by filter function of the stage
+func (stage *parallelStage) run(job *parallelJob) {
+	stage.sem <- struct{}{}
+	job.result, job.err = stage.filterFn(job.rec.line)
+	<-stage.sem
+	stage.finish(job)
+}
+
+// finish - mark the job as processed
+func (stage *parallelStage) finish(job *parallelJob) {
+	if stage.ordered {
+		close(job.done)
+		return
+	}
+	// never blocks, the size of results is the limit of jobs in flight
+	stage.results <- job
+}
+
+// pending - number of started and not returned jobs
+func (stage *parallelStage) pending() int {
+	if stage.ordered {
+		return len(stage.queue)
+	}
+	return stage.inFlight
+}
+
+// pop - wait for the next processed job, the first in order of lines or the first finished
+func (stage *parallelStage) pop() *parallelJob {
+	if !stage.ordered {
+		stage.inFlight--
+		return <-stage.results
+	}
+
+	job := stage.queue[0]
+	stage.queue = stage.queue[1:]
+	<-job.done
+	return job
+}
+
+// stop - stop processing of lines, results of the running jobs are dropped
+func (stage *parallelStage) stop() {
+	stage.done, stage.queue, stage.inFlight = true, nil, 0
+}
+
+// pullRecord - get the next line from the scanner or the previous stage, with applied filter functions before the stage
+func (lr *Reader) pullRecord(stageNum int) (*record, error) {
+	from := 0
+	if stageNum > 0 {
+		from = lr.stages[stageNum-1].index + 1
+	}
+	to := lr.stages[stageNum].index
+
+	for {
+		var lineBytes []byte
+		if stageNum == 0 {
+			// AWK vars of Reader can be restored for the previous lines
+			if stage := lr.stages[0]; stage.vars == nil {
+				stage.vars = &AWKVars{}
+			} else {
+				lr.awkVars = *stage.vars
+			}
+			ok := lr.scan()
+			*lr.stages[0].vars = lr.awkVars
+			if !ok {
+				if err := lr.scanner.Err(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			lineBytes = lr.token
+			lr.line = append(lr.line[:0], lineBytes...)
+		} else {
+			rec, err := lr.stageNext(stageNum - 1)
+			if err != nil {
+				return nil, err
+			}
+			if rec.err != nil {
+				return rec, nil
+			}
+			lr.restore(rec)
+			lineBytes = rec.line
+			if rec.last {
+				// the previous stage is stopped, but this line must be processed
+				lineBytes, ok, err := lr.processFilters(lineBytes, from, to)
+				if err == io.EOF {
+					err = nil
+				}
+				if !ok && err == nil {
+					return nil, io.EOF
+				}
+				return lr.snapshot(lineBytes, err, true), nil
+			}
+		}
+
+		result, ok, err := lr.processFilters(lineBytes, from, to)
+		switch {
+		case err == io.EOF && !ok:
+			return nil, io.EOF
+		case err == io.EOF:
+			return lr.snapshot(result, nil, true), nil
+		case err != nil:
+			return lr.snapshot(result, err, false), nil
+		case ok:
+			return lr.snapshot(result, nil, false), nil
+		}
+	}
+}
+
+// snapshot - save the line with the current state of Reader
+func (lr *Reader) snapshot(lineBytes []byte, err error, last bool) *record {
+	var actives []bool
+	for _, addr := range lr.addrs {
+		actives = append(actives, addr.active)
+	}
+
+	return &record{
+		actives: actives,
+		line:    append([]byte(nil), lineBytes...),
+		orig:    append([]byte(nil), lr.line...),
+		rt:      append([]byte(nil), lr.rt...),
+		vars:    lr.awkVars,
+		err:     err,
+		last:    last,
+	}
+}
+
+// restore - restore the state of Reader for the line
+func (lr *Reader) restore(rec *record) {
+	lr.line = append(lr.line[:0], rec.orig...)
+	lr.rt = rec.rt
+	lr.awkVars = rec.vars
+	for i, active := range rec.actives {
+		lr.addrs[i].active = active
+	}
+}
@@ -0,0 +1,204 @@
+package byline_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelMap(t *testing.T) {
+	var in, expected strings.Builder
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&in, "%d\n", i)
+		if i%3 != 0 {
+			fmt.Fprintf(&expected, "%d:%d\n", i, i*2)
+		}
+	}
+
+	result, err := byline.NewReader(strings.NewReader(in.String())).
+		GrepString(func(line string) bool { return line != "" }).
+		ParallelMap(4, func(line []byte) ([]byte, error) {
+			time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+			num, err := strconv.Atoi(strings.TrimSpace(string(line)))
+			if err != nil {
+				return nil, err
+			}
+			if num%3 == 0 {
+				return nil, byline.ErrOmitLine
+			}
+			return []byte(fmt.Sprintf("%d:%d\n", num, num*2)), nil
+		}).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			return fmt.Sprintf("%d:%s", vars.NR, strings.SplitN(line, ":", 2)[1]), nil
+		}).
+		ReadAllString()
+
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), result)
+}
+
+func TestParallelMapErrors(t *testing.T) {
+	errBad := errors.New("bad")
+	filter := func(line []byte) ([]byte, error) {
+		if strings.HasPrefix(string(line), "bad") {
+			return line, errBad
+		}
+		return line, nil
+	}
+
+	tests := []struct {
+		name       string
+		maxErrors  int
+		workers    int
+		wantResult string
+		wantNR     int
+		wantErr    error
+		wantErrNRs []int
+	}{
+		{name: "stop on first error", workers: 2, wantResult: "1\n2\nbad\n", wantNR: 3, wantErr: errBad},
+		{name: "default workers", workers: 0, wantResult: "1\n2\nbad\n", wantNR: 3, wantErr: errBad},
+		{name: "skip errors", maxErrors: -1, workers: 3, wantResult: "1\n2\n5\n7\n", wantErrNRs: []int{3, 5}},
+		{name: "too many errors", maxErrors: 1, workers: 2, wantResult: "1\n2\n5\nbad2\n", wantErr: byline.ErrTooManyErrors, wantErrNRs: []int{3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := strings.NewReader("1\n2\nbad\n5\nbad2\n7\n")
+			lr := byline.NewReader(reader).
+				SkipErrors(tt.maxErrors).
+				ParallelMap(tt.workers, filter)
+			result, err := lr.ReadAllString()
+
+			require.Equal(t, tt.wantResult, result)
+			var errNRs []int
+			for _, lineErr := range lr.Errors() {
+				errNRs = append(errNRs, lineErr.NR)
+			}
+			require.Equal(t, tt.wantErrNRs, errNRs)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantNR > 0 {
+				var lineErr *byline.LineError
+				require.True(t, errors.As(err, &lineErr))
+				require.Equal(t, tt.wantNR, lineErr.NR)
+				require.Equal(t, "bad\n", string(lineErr.Line))
+				require.Equal(t, 0, lineErr.FilterIndex)
+			}
+		})
+	}
+}
+
+func TestParallelMapEOF(t *testing.T) {
+	result, err := byline.NewReader(strings.NewReader("1\n2\n3\n4\n5\n")).
+		ParallelMap(2, func(line []byte) ([]byte, error) {
+			if string(line) == "3\n" {
+				return line, io.EOF
+			}
+			return line, nil
+		}).
+		ParallelMap(2, func(line []byte) ([]byte, error) {
+			return []byte("> " + string(line)), nil
+		}).
+		ReadAllString()
+
+	require.NoError(t, err)
+	require.Equal(t, "> 1\n> 2\n> 3\n", result)
+}
+
+func TestParallelMapEnd(t *testing.T) {
+	result, err := byline.NewReader(strings.NewReader("1\n2\n3\n4\n")).
+		ParallelMap(2, func(line []byte) ([]byte, error) {
+			if string(line) == "4\n" {
+				return nil, byline.ErrOmitLine
+			}
+			return line, nil
+		}).
+		End(func(vars byline.AWKVars) string {
+			return fmt.Sprintf("total: %d\n", vars.NR)
+		}).
+		ReadAllString()
+
+	require.NoError(t, err)
+	require.Equal(t, "1\n2\n3\ntotal: 4\n", result)
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	var in strings.Builder
+	for i := 1; i <= 500; i++ {
+		fmt.Fprintf(&in, "%d\n", i)
+	}
+
+	lr := byline.NewReader(strings.NewReader(in.String())).
+		ParallelMapUnordered(8, func(line []byte) ([]byte, error) {
+			// the first lines are processed slower than others
+			num, _ := strconv.Atoi(strings.TrimSpace(string(line)))
+			if num <= 5 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			if num%10 == 0 {
+				return nil, byline.ErrOmitLine
+			}
+			if num == 7 {
+				return nil, errors.New("bad")
+			}
+			return line, nil
+		}).
+		SkipErrors(-1).
+		AWKMode(func(line string, fields []string, vars byline.AWKVars) (string, error) {
+			if line != strconv.Itoa(vars.NR) {
+				return "", fmt.Errorf("NR %d is not for line %q", vars.NR, line)
+			}
+			return line, nil
+		})
+
+	lines, err := lr.ReadAllSliceString()
+	require.NoError(t, err)
+	require.Len(t, lines, 449)
+	require.NotEqual(t, "1\n", lines[0], "the slow first line must not block others")
+
+	sorted := make([]int, 0, len(lines))
+	for _, line := range lines {
+		num, err := strconv.Atoi(strings.TrimSpace(line))
+		require.NoError(t, err)
+		sorted = append(sorted, num)
+	}
+	sort.Ints(sorted)
+	require.Equal(t, 1, sorted[0])
+	require.Equal(t, 499, sorted[len(sorted)-1])
+
+	require.Len(t, lr.Errors(), 1)
+	require.Equal(t, 7, lr.Errors()[0].NR)
+}
+
+func TestParallelMapUnorderedEOF(t *testing.T) {
+	result, err := byline.NewReader(strings.NewReader("1\n2\n3\n4\n5\n")).
+		GrepString(func(line string) bool {
+			return line != "2\n"
+		}).
+		MapStringErr(func(line string) (string, error) {
+			if line == "4\n" {
+				return line, io.EOF
+			}
+			return line, nil
+		}).
+		ParallelMapUnordered(2, func(line []byte) ([]byte, error) {
+			return []byte("> " + string(line)), nil
+		}).
+		ReadAllSliceString()
+
+	require.NoError(t, err)
+	sort.Strings(result)
+	require.Equal(t, []string{"> 1\n", "> 3\n", "> 4\n"}, result)
+}
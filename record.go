@@ -0,0 +1,137 @@
+package byline
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownColumn - error for column which is absent in the header
+	ErrUnknownColumn = errors.New("unknown column")
+
+	// ErrMissingField - error for column which is present in the header but absent in the current line
+	ErrMissingField = errors.New("missing field")
+)
+
+// Record - line in AWKModeHeader with fields accessible by column names from the header
+type Record struct {
+	Line   string   // current line without record separator
+	Fields []string // fields of the current line
+	header *header
+}
+
+// header - column names from the header line
+type header struct {
+	columns []string
+	index   map[string]int
+}
+
+func newHeader(columns []string) *header {
+	h := &header{
+		columns: make([]string, len(columns)),
+		index:   make(map[string]int, len(columns)),
+	}
+	for i, column := range columns {
+		h.columns[i] = strings.TrimSpace(column)
+		if _, exists := h.index[h.columns[i]]; !exists {
+			h.index[h.columns[i]] = i
+		}
+	}
+
+	return h
+}
+
+// Header - get column names from the header line
+func (rec Record) Header() []string {
+	if rec.header == nil {
+		return nil
+	}
+	return rec.header.columns
+}
+
+// Has - check if the column exists in the header and in the current line
+func (rec Record) Has(name string) bool {
+	_, err := rec.Get(name)
+	return err == nil
+}
+
+// Get - get field by column name
+func (rec Record) Get(name string) (string, error) {
+	if rec.header == nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+	}
+	i, ok := rec.header.index[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+	}
+	if i >= len(rec.Fields) {
+		return "", fmt.Errorf("%w: %q", ErrMissingField, name)
+	}
+
+	return rec.Fields[i], nil
+}
+
+// Int - get field by column name as int
+func (rec Record) Int(name string) (int, error) {
+	field, err := rec.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	result, err := strconv.Atoi(strings.TrimSpace(field))
+	if err != nil {
+		return 0, fmt.Errorf("column %q: %w", name, err)
+	}
+	return result, nil
+}
+
+// Float - get field by column name as float64
+func (rec Record) Float(name string) (float64, error) {
+	field, err := rec.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	result, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+	if err != nil {
+		return 0, fmt.Errorf("column %q: %w", name, err)
+	}
+	return result, nil
+}
+
+// Bool - get field by column name as bool, accepts values as strconv.ParseBool
+func (rec Record) Bool(name string) (bool, error) {
+	field, err := rec.Get(name)
+	if err != nil {
+		return false, err
+	}
+	result, err := strconv.ParseBool(strings.TrimSpace(field))
+	if err != nil {
+		return false, fmt.Errorf("column %q: %w", name, err)
+	}
+	return result, nil
+}
+
+// AWKModeHeader - process lines with AWK like mode, the first line of each input is the header with column names,
+// fields of the next lines are accessible by the names via Record methods. The header line is omitted from output.
+func (lr *Reader) AWKModeHeader(filterFn func(rec Record, vars AWKVars) (string, error)) *Reader {
+	if lr == nil {
+		return nil
+	}
+
+	var (
+		hdr     *header
+		lastFNR int
+	)
+	return lr.AWKMode(func(line string, fields []string, vars AWKVars) (string, error) {
+		// FNR is reset for the next input of multi reader
+		isHeader := hdr == nil || vars.FNR <= lastFNR
+		lastFNR = vars.FNR
+		if isHeader {
+			hdr = newHeader(fields)
+			return "", ErrOmitLine
+		}
+
+		return filterFn(Record{Line: line, Fields: fields, header: hdr}, vars)
+	})
+}
@@ -0,0 +1,75 @@
+package byline_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWKModeHeader(t *testing.T) {
+	reader := strings.NewReader("id,price, name ,active\n1,12.5,one,true\n2,7,two,0\n")
+
+	result, err := byline.NewReader(reader).
+		SetCSV(byline.CSVOptions{}).
+		AWKModeHeader(func(rec byline.Record, vars byline.AWKVars) (string, error) {
+			require.Equal(t, []string{"id", "price", "name", "active"}, rec.Header())
+			require.True(t, rec.Has("price"))
+			require.False(t, rec.Has("unknown"))
+
+			id, err := rec.Int("id")
+			require.NoError(t, err)
+			price, err := rec.Float("price")
+			require.NoError(t, err)
+			name, err := rec.Get("name")
+			require.NoError(t, err)
+			active, err := rec.Bool("active")
+			require.NoError(t, err)
+
+			return fmt.Sprintf("%d:%s:%.2f:%v:%d", id, name, price, active, vars.NR), nil
+		}).
+		ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "1:one:12.50:true:2\n2:two:7.00:false:3\n", result)
+}
+
+func TestAWKModeHeaderErrors(t *testing.T) {
+	reader := strings.NewReader("id,price\n1,12.5\n2\n3,x\n")
+
+	errs := []error{}
+	err := byline.NewReader(reader).
+		SetCSV(byline.CSVOptions{}).
+		AWKModeHeader(func(rec byline.Record, vars byline.AWKVars) (string, error) {
+			_, err := rec.Get("name")
+			require.ErrorIs(t, err, byline.ErrUnknownColumn)
+			require.Equal(t, `unknown column: "name"`, err.Error())
+
+			_, err = rec.Float("price")
+			errs = append(errs, err)
+			return rec.Line, nil
+		}).
+		Discard()
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], byline.ErrMissingField)
+	require.ErrorIs(t, errs[2], strconv.ErrSyntax)
+	require.Equal(t, `column "price": strconv.ParseFloat: parsing "x": invalid syntax`, errs[2].Error())
+}
+
+func TestAWKModeHeaderMultiReader(t *testing.T) {
+	lr := byline.NewMultiReader(
+		byline.NamedReader{Name: "a", Reader: strings.NewReader("id name\n1 one\n2 two\n")},
+		byline.NamedReader{Name: "b", Reader: strings.NewReader("name id\nthree 3\n")},
+	)
+
+	result, err := lr.AWKModeHeader(func(rec byline.Record, vars byline.AWKVars) (string, error) {
+		id, err := rec.Int("id")
+		return vars.FILENAME + ":" + strconv.Itoa(id), err
+	}).ReadAllString()
+	require.NoError(t, err)
+	require.Equal(t, "a:1\na:2\nb:3\n", result)
+}
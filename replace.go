@@ -0,0 +1,75 @@
+package byline
+
+import (
+	"regexp"
+)
+
+// Replace - replace all matches of regexp in lines like s/re/repl/g in sed,
+// repl can contain $1, ${name} for submatches (see regexp.Expand), record separator is not changed
+func (lr *Reader) Replace(re *regexp.Regexp, repl []byte) *Reader {
+	return lr.ReplaceN(re, repl, -1)
+}
+
+// ReplaceN - replace the first n matches of regexp in lines (n < 0 - all matches),
+// repl can contain $1, ${name} for submatches, record separator is not changed
+func (lr *Reader) ReplaceN(re *regexp.Regexp, repl []byte, n int) *Reader {
+	return lr.ReplaceIf(nil, re, repl, n)
+}
+
+// ReplaceIf - replace the first n matches of regexp (n < 0 - all matches) only in lines matched by addr regexp,
+// like /addr/s/re/repl/g in sed, other lines are not changed
+func (lr *Reader) ReplaceIf(addr, re *regexp.Regexp, repl []byte, n int) *Reader {
+	if lr == nil {
+		return nil
+	}
+	return lr.replaceLine(addr, func(line []byte) []byte {
+		return replaceN(re, line, repl, n)
+	})
+}
+
+// ReplaceFunc - replace all matches of regexp in lines by result of replFn, record separator is not changed
+func (lr *Reader) ReplaceFunc(re *regexp.Regexp, replFn func([]byte) []byte) *Reader {
+	if lr == nil {
+		return nil
+	}
+	return lr.replaceLine(nil, func(line []byte) []byte {
+		return re.ReplaceAllFunc(line, replFn)
+	})
+}
+
+// replaceLine - apply replace function to the line without record separator, if addr is not nil - only for matched lines
+func (lr *Reader) replaceLine(addr *regexp.Regexp, replFn func([]byte) []byte) *Reader {
+	return lr.Map(func(line []byte) []byte {
+		body, RS := lr.trimRS(line)
+		if addr != nil && !addr.Match(body) {
+			return line
+		}
+
+		result := replFn(body)
+		if len(RS) == 0 {
+			return result
+		}
+		// result can share memory with the line
+		return append(result[:len(result):len(result)], RS...)
+	})
+}
+
+// replaceN - replace the first n matches of regexp in the line with expanding of submatches
+func replaceN(re *regexp.Regexp, line, repl []byte, n int) []byte {
+	if n == 0 {
+		return line
+	}
+	matches := re.FindAllSubmatchIndex(line, n)
+	if len(matches) == 0 {
+		return line
+	}
+
+	result := make([]byte, 0, len(line)+len(repl)*len(matches))
+	last := 0
+	for _, match := range matches {
+		result = append(result, line[last:match[0]]...)
+		result = re.Expand(result, repl, line, match)
+		last = match[1]
+	}
+	return append(result, line[last:]...)
+}
@@ -0,0 +1,93 @@
+package byline_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/msoap/byline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		filter func(lr *byline.Reader) *byline.Reader
+		out    string
+	}{
+		{
+			name: "replace all",
+			in:   "a1 b2\nc3\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Replace(regexp.MustCompile(`\d`), []byte("#"))
+			},
+			out: "a# b#\nc#\n",
+		},
+		{
+			name: "submatches",
+			in:   "key=value\nname=byline",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Replace(regexp.MustCompile(`(\w+)=(?P<val>\w+)`), []byte("${val}:$1"))
+			},
+			out: "value:key\nbyline:name",
+		},
+		{
+			name: "not eat RS",
+			in:   "a\nb\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.Replace(regexp.MustCompile(`\s*$`), []byte(";"))
+			},
+			out: "a;\nb;\n",
+		},
+		{
+			name: "not eat multi-byte RS",
+			in:   "a  \r\nb\r\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.SetRSString("\r\n").Replace(regexp.MustCompile(`\s+`), []byte(""))
+			},
+			out: "a\r\nb\r\n",
+		},
+		{
+			name: "first n",
+			in:   "aaaa\naa\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.ReplaceN(regexp.MustCompile(`a`), []byte("b"), 3)
+			},
+			out: "bbba\nbb\n",
+		},
+		{
+			name: "zero n",
+			in:   "aaaa\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.ReplaceN(regexp.MustCompile(`a`), []byte("b"), 0)
+			},
+			out: "aaaa\n",
+		},
+		{
+			name: "address",
+			in:   "# a=1\na=2\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.ReplaceIf(regexp.MustCompile(`^#`), regexp.MustCompile(`\d`), []byte("<$0>"), -1)
+			},
+			out: "# a=<1>\na=2\n",
+		},
+		{
+			name: "func",
+			in:   "abc def\n",
+			filter: func(lr *byline.Reader) *byline.Reader {
+				return lr.ReplaceFunc(regexp.MustCompile(`\w+`), bytes.ToUpper)
+			},
+			out: "ABC DEF\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.filter(byline.NewReader(strings.NewReader(tt.in))).ReadAllString()
+			require.NoError(t, err)
+			require.Equal(t, tt.out, result)
+		})
+	}
+}